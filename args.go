@@ -0,0 +1,59 @@
+package errors
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// MetaArgPrefix is the prefix of the metadata keys of the arguments
+// recorded by WrapArgs, followed by their index.
+const MetaArgPrefix = "arg"
+
+var (
+	// ArgMaxLength is the maximum number of bytes of the arguments
+	// recorded by WrapArgs and WithQuery when RawArgs is set.
+	ArgMaxLength = 64
+	// RawArgs makes WrapArgs and WithQuery record the arguments
+	// themselves, truncated, instead of SecretMask, e.g. during
+	// development. The arguments wrapped with NewSecret stay masked.
+	RawArgs = false
+)
+
+// WrapArgs is like Wrap without message, and records the arguments
+// of the failed call as strings in the metadata, under arg0 to argN,
+// to tell which inputs caused the failure. The arguments are
+// recorded as SecretMask, keeping only their number, unless RawArgs
+// is set. If err is nil, WrapArgs returns a true nil error.
+func WrapArgs(err error, op string, args ...any) error {
+	if err == nil {
+		return nil
+	}
+	e := buildError(1, err, "", DefaultCode, op)
+	if len(args) > 0 && e.Metadata == nil {
+		e.Metadata = make(map[string]any, len(args))
+	}
+	for i, arg := range args {
+		e.Metadata[MetaArgPrefix+strconv.Itoa(i)] = truncateString(fmt.Sprint(recordedArg(arg)), ArgMaxLength)
+	}
+	notify(e)
+	return e
+}
+
+// recordedArg returns the argument as recorded by WrapArgs and
+// WithQuery: SecretMask unless RawArgs is set, or else the argument
+// with its strings and byte slices truncated to ArgMaxLength.
+func recordedArg(arg any) any {
+	if _, ok := arg.(Secret); ok || !RawArgs {
+		return SecretMask
+	}
+	switch v := arg.(type) {
+	case string:
+		return truncateString(v, ArgMaxLength)
+	case []byte:
+		if len(v) > ArgMaxLength {
+			return append(v[:ArgMaxLength:ArgMaxLength], "..."...)
+		}
+		return append([]byte(nil), v...)
+	}
+	return arg
+}
@@ -0,0 +1,137 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	// MetaActor is the metadata key of the user or service which
+	// performed the operation, read by AuditHook.
+	MetaActor = "actor"
+	// MetaResource is the metadata key of the resource the operation
+	// applied to, read by AuditHook.
+	MetaResource = "resource"
+)
+
+// AuditEvent is a security relevant failure, such as a denied
+// access, emitted by an AuditHook.
+type AuditEvent struct {
+	Time      time.Time `json:"time"`
+	Code      string    `json:"code"`
+	Operation string    `json:"operation,omitempty"`
+	Actor     string    `json:"actor,omitempty"`
+	Resource  string    `json:"resource,omitempty"`
+	Message   string    `json:"message,omitempty"`
+	ErrorID   string    `json:"error_id"`
+	TraceID   string    `json:"trace_id,omitempty"`
+}
+
+// AuditSink receives the audit events, e.g. to forward them to a
+// central audit log.
+type AuditSink interface {
+	Audit(ctx context.Context, event AuditEvent) error
+}
+
+// AuditSinkFunc adapts an ordinary function to an AuditSink.
+type AuditSinkFunc func(ctx context.Context, event AuditEvent) error
+
+// Audit calls f(ctx, event).
+func (f AuditSinkFunc) Audit(ctx context.Context, event AuditEvent) error {
+	return f(ctx, event)
+}
+
+// AuditHook converts the errors of selected codes into audit events
+// sent to a sink. Feed it with Observe, for instance from the
+// DefaultErrorCallbackHandler, or use it as a Reporter.
+type AuditHook struct {
+	// Codes are the audited codes. Descendants of hierarchical codes
+	// are audited too.
+	Codes []string
+	// Sink receives the events.
+	Sink AuditSink
+	// ActorKey and ResourceKey are the metadata keys of the actor
+	// and the resource. They default to MetaActor and MetaResource.
+	ActorKey    string
+	ResourceKey string
+}
+
+// NewAuditHook returns an AuditHook sending the errors of the codes
+// to sink. FORBIDDEN is audited when no code is given.
+func NewAuditHook(sink AuditSink, codes ...string) *AuditHook {
+	if len(codes) == 0 {
+		codes = []string{FORBIDDEN}
+	}
+	return &AuditHook{Codes: codes, Sink: sink}
+}
+
+// Observe sends the audit event of err, if its code is audited. It
+// can be used as an ErrorCallbackHandler.
+func (h *AuditHook) Observe(err *Error) {
+	ctx := context.Background()
+	if err != nil && err.Context != nil {
+		ctx = err.Context
+	}
+	_ = h.Report(ctx, err)
+}
+
+// Report sends the audit event of err, if its code is audited, and
+// returns the error of the sink. It implements Reporter.
+func (h *AuditHook) Report(ctx context.Context, err *Error) error {
+	event, ok := h.Event(err)
+	if !ok || h.Sink == nil {
+		return nil
+	}
+	return h.Sink.Audit(ctx, event)
+}
+
+// Event returns the audit event of err, and false if its code is
+// not audited.
+func (h *AuditHook) Event(err *Error) (AuditEvent, bool) {
+	if err == nil {
+		return AuditEvent{}, false
+	}
+	code := Code(err)
+	audited := false
+	for _, c := range h.Codes {
+		if hasCodePrefix(code, c) {
+			audited = true
+			break
+		}
+	}
+	if !audited {
+		return AuditEvent{}, false
+	}
+	actorKey, resourceKey := h.ActorKey, h.ResourceKey
+	if actorKey == "" {
+		actorKey = MetaActor
+	}
+	if resourceKey == "" {
+		resourceKey = MetaResource
+	}
+	return AuditEvent{
+		Time:      currentTime().UTC(),
+		Code:      code,
+		Operation: err.Operation,
+		Actor:     metaString(err, actorKey),
+		Resource:  metaString(err, resourceKey),
+		Message:   Message(err),
+		ErrorID:   err.ID(),
+		TraceID:   metaString(err, MetaTraceID),
+	}, true
+}
+
+// metaString returns the metadata value of the first Error of the
+// chain having key, as a string.
+func metaString(err error, key string) string {
+	for _, e := range chainOf(err) {
+		if v, ok := e.Meta(key); ok && v != nil {
+			if s, ok := v.(string); ok {
+				return s
+			}
+			return fmt.Sprint(v)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,151 @@
+package errors
+
+import (
+	"sync"
+	"time"
+)
+
+// maxBackoffHistory is the number of fingerprints above which the
+// expired histories are pruned.
+const maxBackoffHistory = 1024
+
+// BackoffAdvisor suggests exponential retry delays from the recent
+// failures of every fingerprint, so that the call sites producing
+// the same error back off together. Feed it with Observe, for
+// instance from the DefaultErrorCallbackHandler, which attaches the
+// suggested delay to the errors as their RetryAfter.
+type BackoffAdvisor struct {
+	// Base is the delay suggested after the first failure, doubled
+	// after every further failure up to Max.
+	Base time.Duration
+	Max  time.Duration
+	// Window is the period without failures after which the history
+	// of a fingerprint is forgotten. Zero means twice Max.
+	Window time.Duration
+	// Codes lists the codes, or their parents, of the errors which
+	// Observe advises although they are not retryable.
+	Codes []string
+
+	mu      sync.Mutex
+	history map[string]*backoffHistory
+}
+
+type backoffHistory struct {
+	failures int
+	last     time.Time
+}
+
+// NewBackoffAdvisor returns a BackoffAdvisor with the given base
+// and maximum delays.
+func NewBackoffAdvisor(base, max time.Duration) *BackoffAdvisor {
+	return &BackoffAdvisor{Base: base, Max: max}
+}
+
+// Observe records a failure of the fingerprint of err and, unless
+// err already has one, attaches the suggested delay as its
+// RetryAfter. Only the retryable errors, see IsRetryable, and the
+// errors of the Codes are observed, so that the advisor never
+// makes a validation failure look worth retrying. It can be used as
+// an ErrorCallbackHandler.
+func (a *BackoffAdvisor) Observe(err *Error) {
+	if err == nil || !IsRetryable(err) && !a.advises(err.Code) {
+		return
+	}
+	d := a.Fail(err.Fingerprint())
+	if _, ok := err.Meta(MetaRetryAfter); !ok {
+		err.WithRetryAfter(d)
+	}
+}
+
+func (a *BackoffAdvisor) advises(code string) bool {
+	for _, c := range a.Codes {
+		if hasCodePrefix(code, c) {
+			return true
+		}
+	}
+	return false
+}
+
+// Fail records a failure of the fingerprint and returns the delay
+// to wait before the next attempt.
+func (a *BackoffAdvisor) Fail(fingerprint string) time.Duration {
+	now := currentTime()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.history == nil {
+		a.history = make(map[string]*backoffHistory)
+	}
+	h, ok := a.history[fingerprint]
+	if !ok || a.expired(h, now) {
+		if len(a.history) >= maxBackoffHistory {
+			a.prune(now)
+		}
+		h = &backoffHistory{}
+		a.history[fingerprint] = h
+	}
+	h.failures++
+	h.last = now
+	return a.delay(h.failures)
+}
+
+// Next returns the delay suggested for the fingerprint, or 0 if it
+// has no recent failures.
+func (a *BackoffAdvisor) Next(fingerprint string) time.Duration {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	h, ok := a.history[fingerprint]
+	if !ok || a.expired(h, currentTime()) {
+		return 0
+	}
+	return a.delay(h.failures)
+}
+
+// Reset forgets the failures of the fingerprint, e.g. after a
+// successful attempt.
+func (a *BackoffAdvisor) Reset(fingerprint string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.history, fingerprint)
+}
+
+// limits returns the base and maximum delays, with defaults of
+// 100ms and one minute.
+func (a *BackoffAdvisor) limits() (base, max time.Duration) {
+	base, max = a.Base, a.Max
+	if base <= 0 {
+		base = 100 * time.Millisecond
+	}
+	if max <= 0 {
+		max = time.Minute
+	}
+	return base, max
+}
+
+func (a *BackoffAdvisor) delay(failures int) time.Duration {
+	base, max := a.limits()
+	d := base
+	for i := 1; i < failures && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	return d
+}
+
+func (a *BackoffAdvisor) expired(h *backoffHistory, now time.Time) bool {
+	window := a.Window
+	if window <= 0 {
+		_, max := a.limits()
+		window = 2 * max
+	}
+	return now.Sub(h.last) > window
+}
+
+func (a *BackoffAdvisor) prune(now time.Time) {
+	for fingerprint, h := range a.history {
+		if a.expired(h, now) {
+			delete(a.history, fingerprint)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package errors_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/oarkflow/errors"
+)
+
+func TestBackoffAdvisorOnlyRetryable(t *testing.T) {
+	a := errors.NewBackoffAdvisor(time.Second, time.Minute)
+	for _, e := range []*errors.Error{
+		errors.NewNotFound(nil, "no user", "users.Get", true),
+		errors.NewInvalid(nil, "bad email", "users.Create", true),
+	} {
+		a.Observe(e)
+		if errors.IsRetryable(e) {
+			t.Errorf("%s error made retryable", e.Code)
+		}
+		if _, ok := errors.RetryAfter(e); ok {
+			t.Errorf("%s error advised", e.Code)
+		}
+	}
+
+	unavailable := errors.NewE(nil, "db down", "db.Query", true)
+	unavailable.Retryable = true
+	a.Observe(unavailable)
+	if d, ok := errors.RetryAfter(unavailable); !ok || d != time.Second {
+		t.Errorf("RetryAfter of a retryable error = %v, %v, want 1s", d, ok)
+	}
+
+	a.Codes = []string{errors.NOTFOUND}
+	notFound := errors.NewNotFound(nil, "no user", "users.Get", true)
+	a.Observe(notFound)
+	if _, ok := errors.RetryAfter(notFound); !ok {
+		t.Error("error of a configured code not advised")
+	}
+	if errors.IsRetryable(notFound) {
+		t.Error("a retry delay alone made the error retryable")
+	}
+}
@@ -0,0 +1,276 @@
+package errors
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+)
+
+var (
+	// ErrInvalidBinary is returned when decoding malformed binary
+	// encoded errors.
+	ErrInvalidBinary = New("errors: invalid binary encoding")
+	// ErrBinaryTooLarge is returned when a binary encoded error
+	// exceeds MaxBinarySize.
+	ErrBinaryTooLarge = New("errors: binary encoded error too large")
+)
+
+// MaxBinarySize is the maximum size of a binary encoded error
+// accepted by DecodeBinary and ReadBinary.
+var MaxBinarySize = 16 << 20
+
+// Field tags of the binary encoding. Every field is encoded as a
+// varint tag, a varint length and the field bytes, so decoders
+// skip the tags they do not know.
+const (
+	binCode = iota + 1
+	binMessage
+	binOperation
+	binErr
+	binFileLine
+	binInternal
+	binSeverity
+	binRetryable
+	binMetadata
+	binFrame
+	binID
+	binViolations
+)
+
+// Field tags of an encoded stack frame.
+const (
+	binFrameFunction = iota + 1
+	binFrameFile
+	binFrameLine
+)
+
+// EncodeBinary returns the compact binary encoding of the error,
+// prefixed by its varint length. Metadata and violations are
+// JSON encoded. The stack frames are only included if withStack
+// is true.
+func EncodeBinary(e *Error, withStack bool) ([]byte, error) {
+	return AppendBinary(nil, e, withStack)
+}
+
+// AppendBinary appends the encoding of EncodeBinary to dst.
+func AppendBinary(dst []byte, e *Error, withStack bool) ([]byte, error) {
+	if e == nil {
+		return binary.AppendUvarint(dst, 0), nil
+	}
+	var body []byte
+	body = appendString(body, binCode, e.Code)
+	body = appendString(body, binMessage, e.Message)
+	body = appendString(body, binOperation, e.Operation)
+	if e.Err != nil {
+		body = appendString(body, binErr, e.Err.Error())
+	}
+	body = appendString(body, binFileLine, e.fileLine)
+	body = appendUint(body, binInternal, boolUint(e.Internal))
+	body = appendUint(body, binSeverity, uint64(e.Severity))
+	body = appendUint(body, binRetryable, boolUint(e.Retryable))
+	if metadata := e.metadata(); len(metadata) > 0 {
+		b, err := json.Marshal(metadata)
+		if err != nil {
+			return dst, err
+		}
+		body = appendField(body, binMetadata, b)
+	}
+	if len(e.Violations) > 0 {
+		b, err := json.Marshal(e.Violations)
+		if err != nil {
+			return dst, err
+		}
+		body = appendField(body, binViolations, b)
+	}
+	e.mu.RLock()
+	id := e.id
+	e.mu.RUnlock()
+	body = appendString(body, binID, id)
+	if withStack {
+		for _, t := range e.Additional {
+			var frame []byte
+			frame = appendString(frame, binFrameFunction, t.Function)
+			frame = appendString(frame, binFrameFile, t.File)
+			frame = appendUint(frame, binFrameLine, uint64(t.Line))
+			body = appendField(body, binFrame, frame)
+		}
+	}
+	dst = binary.AppendUvarint(dst, uint64(len(body)))
+	return append(dst, body...), nil
+}
+
+// DecodeBinary decodes the first error encoded in data by
+// EncodeBinary and returns it with the number of bytes read. A
+// nil error encoding decodes to nil.
+func DecodeBinary(data []byte) (*Error, int, error) {
+	size, n := binary.Uvarint(data)
+	if n <= 0 {
+		return nil, 0, ErrInvalidBinary
+	}
+	if size > uint64(MaxBinarySize) {
+		return nil, 0, ErrBinaryTooLarge
+	}
+	if uint64(len(data)-n) < size {
+		return nil, 0, ErrInvalidBinary
+	}
+	end := n + int(size)
+	if size == 0 {
+		return nil, end, nil
+	}
+	e, err := decodeBinaryBody(data[n:end])
+	if err != nil {
+		return nil, 0, err
+	}
+	return e, end, nil
+}
+
+// ReadBinary reads the next error encoded by EncodeBinary from r.
+// It returns io.EOF when r holds no more errors.
+func ReadBinary(r *bufio.Reader) (*Error, error) {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		return nil, ErrInvalidBinary
+	}
+	if size > uint64(MaxBinarySize) {
+		return nil, ErrBinaryTooLarge
+	}
+	if size == 0 {
+		return nil, nil
+	}
+	// The body grows with the bytes actually read, so a forged
+	// length prefix does not allocate MaxBinarySize up front.
+	body, err := io.ReadAll(io.LimitReader(r, int64(size)))
+	if err != nil || uint64(len(body)) != size {
+		return nil, ErrInvalidBinary
+	}
+	return decodeBinaryBody(body)
+}
+
+func decodeBinaryBody(body []byte) (*Error, error) {
+	e := &Error{}
+	err := readFields(body, func(tag uint64, value []byte) error {
+		switch tag {
+		case binCode:
+			e.Code = string(value)
+		case binMessage:
+			e.Message = string(value)
+		case binOperation:
+			e.Operation = string(value)
+		case binErr:
+			e.Err = New(string(value))
+		case binFileLine:
+			e.fileLine = string(value)
+		case binInternal:
+			v, err := readUint(value)
+			e.Internal = v != 0
+			return err
+		case binSeverity:
+			v, err := readUint(value)
+			e.Severity = Severity(v)
+			return err
+		case binRetryable:
+			v, err := readUint(value)
+			e.Retryable = v != 0
+			return err
+		case binMetadata:
+			if err := json.Unmarshal(value, &e.Metadata); err != nil {
+				return ErrInvalidBinary
+			}
+		case binViolations:
+			if err := json.Unmarshal(value, &e.Violations); err != nil {
+				return ErrInvalidBinary
+			}
+		case binID:
+			e.id = string(value)
+		case binFrame:
+			t := Trace{Index: len(e.Additional)}
+			if err := readFields(value, func(tag uint64, value []byte) error {
+				switch tag {
+				case binFrameFunction:
+					t.Function = string(value)
+				case binFrameFile:
+					t.File = string(value)
+				case binFrameLine:
+					v, err := readUint(value)
+					t.Line = int(v)
+					return err
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+			e.Additional = append(e.Additional, t)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// readFields calls fn with the tag and bytes of every field of
+// data.
+func readFields(data []byte, fn func(tag uint64, value []byte) error) error {
+	for len(data) > 0 {
+		tag, n := binary.Uvarint(data)
+		if n <= 0 {
+			return ErrInvalidBinary
+		}
+		data = data[n:]
+		size, n := binary.Uvarint(data)
+		if n <= 0 || uint64(len(data)-n) < size {
+			return ErrInvalidBinary
+		}
+		value := data[n : n+int(size)]
+		data = data[n+int(size):]
+		if err := fn(tag, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readUint(value []byte) (uint64, error) {
+	v, n := binary.Uvarint(value)
+	if n <= 0 || n != len(value) {
+		return 0, ErrInvalidBinary
+	}
+	return v, nil
+}
+
+func appendField(dst []byte, tag uint64, value []byte) []byte {
+	dst = binary.AppendUvarint(dst, tag)
+	dst = binary.AppendUvarint(dst, uint64(len(value)))
+	return append(dst, value...)
+}
+
+// appendString appends a string field, unless it is empty.
+func appendString(dst []byte, tag uint64, s string) []byte {
+	if s == "" {
+		return dst
+	}
+	dst = binary.AppendUvarint(dst, tag)
+	dst = binary.AppendUvarint(dst, uint64(len(s)))
+	return append(dst, s...)
+}
+
+// appendUint appends a varint field, unless it is zero.
+func appendUint(dst []byte, tag, v uint64) []byte {
+	if v == 0 {
+		return dst
+	}
+	var buf [binary.MaxVarintLen64]byte
+	return appendField(dst, tag, buf[:binary.PutUvarint(buf[:], v)])
+}
+
+func boolUint(b bool) uint64 {
+	if b {
+		return 1
+	}
+	return 0
+}
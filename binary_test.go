@@ -0,0 +1,96 @@
+package errors_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"runtime"
+	"testing"
+
+	"github.com/oarkflow/errors"
+)
+
+func encoded(t testing.TB) []byte {
+	t.Helper()
+	e := errors.NewInvalid(errors.New("bad input"), "invalid request", "users.create").
+		WithField("field", "email")
+	b, err := errors.EncodeBinary(e, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestDecodeBinaryTruncated(t *testing.T) {
+	b := encoded(t)
+	for i := 0; i < len(b); i++ {
+		if _, _, err := errors.DecodeBinary(b[:i]); err == nil {
+			t.Fatalf("DecodeBinary(%d of %d bytes) succeeded", i, len(b))
+		}
+		if _, err := errors.ReadBinary(bufio.NewReader(bytes.NewReader(b[:i]))); err == nil {
+			t.Fatalf("ReadBinary(%d of %d bytes) succeeded", i, len(b))
+		}
+	}
+}
+
+func TestDecodeBinaryOversized(t *testing.T) {
+	tests := map[string][]byte{
+		"over MaxBinarySize": binary.AppendUvarint(nil, uint64(errors.MaxBinarySize)+1),
+		"max uint64":         binary.AppendUvarint(nil, ^uint64(0)),
+		"overlong varint":    bytes.Repeat([]byte{0xff}, 11),
+	}
+	for name, b := range tests {
+		if _, _, err := errors.DecodeBinary(b); err == nil {
+			t.Errorf("%s: DecodeBinary succeeded", name)
+		}
+		if _, err := errors.ReadBinary(bufio.NewReader(bytes.NewReader(b))); err == nil || err == io.EOF {
+			t.Errorf("%s: ReadBinary = %v, want a decoding error", name, err)
+		}
+	}
+}
+
+func TestDecodeBinaryOversizedField(t *testing.T) {
+	// A well-formed body holding a field whose length exceeds
+	// the body.
+	var body []byte
+	body = binary.AppendUvarint(body, 1)
+	body = binary.AppendUvarint(body, 1<<40)
+	b := append(binary.AppendUvarint(nil, uint64(len(body))), body...)
+	if _, _, err := errors.DecodeBinary(b); err == nil {
+		t.Error("DecodeBinary succeeded")
+	}
+	if _, err := errors.ReadBinary(bufio.NewReader(bytes.NewReader(b))); err == nil {
+		t.Error("ReadBinary succeeded")
+	}
+}
+
+func TestReadBinaryForgedLengthAllocation(t *testing.T) {
+	b := append(binary.AppendUvarint(nil, uint64(errors.MaxBinarySize)), "short"...)
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	_, err := errors.ReadBinary(bufio.NewReader(bytes.NewReader(b)))
+	runtime.ReadMemStats(&after)
+	if err == nil {
+		t.Fatal("ReadBinary succeeded")
+	}
+	if n := after.TotalAlloc - before.TotalAlloc; n > 1<<20 {
+		t.Errorf("ReadBinary allocated %d bytes for a %d byte input", n, len(b))
+	}
+}
+
+func FuzzReadBinary(f *testing.F) {
+	f.Add(encoded(f))
+	f.Add([]byte{0})
+	f.Add(binary.AppendUvarint(nil, uint64(errors.MaxBinarySize)))
+	f.Add(bytes.Repeat([]byte{0xff}, 11))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		r := bufio.NewReader(bytes.NewReader(data))
+		for i := 0; i <= len(data); i++ {
+			if _, err := errors.ReadBinary(r); err != nil {
+				return
+			}
+		}
+		t.Fatalf("ReadBinary decoded more errors than the %d input bytes", len(data))
+	})
+}
@@ -0,0 +1,162 @@
+package errors
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// MetaBudgetKey is the metadata key of the tenant, API key or
+	// other key an error counts against in a Budget.
+	MetaBudgetKey = "budget_key"
+	// MetaBudgetLimit is the metadata key of the error budget of a
+	// key, set by Budget.Annotate and Budget.Check.
+	MetaBudgetLimit = "budget_limit"
+	// MetaBudgetRemaining is the metadata key of the errors a key may
+	// still produce in the window, set by Budget.Annotate and
+	// Budget.Check.
+	MetaBudgetRemaining = "budget_remaining"
+)
+
+// maxBudgetKeys is the number of keys above which the keys without
+// errors in the window are pruned.
+const maxBudgetKeys = 1024
+
+// Budget tracks the errors of every key, such as a tenant or an API
+// key, over a sliding window, and rejects the keys which exhausted
+// their budget. Feed it with Observe, for instance from the
+// DefaultErrorCallbackHandler, or with Record like a StatsCollector,
+// and call Check before serving a key.
+type Budget struct {
+	// Limit is the number of errors a key may produce within Window.
+	// Values below 1 mean 1.
+	Limit  int
+	Window time.Duration
+	// Key returns the key an error counts against, or "" if none.
+	// It defaults to the MetaBudgetKey metadata of the chain.
+	Key func(err *Error) string
+
+	mu   sync.Mutex
+	seen map[string][]time.Time
+}
+
+// NewBudget returns a Budget allowing limit errors per key within
+// window.
+func NewBudget(limit int, window time.Duration) *Budget {
+	return &Budget{Limit: limit, Window: window}
+}
+
+// Observe counts err against its key. The rejections returned by
+// Check are not counted. It can be used as an ErrorCallbackHandler.
+func (b *Budget) Observe(err *Error) {
+	if err == nil {
+		return
+	}
+	if _, ok := err.Meta(MetaBudgetLimit); ok && err.Code == MAXIMUMATTEMPTS {
+		return
+	}
+	key := ""
+	if b.Key != nil {
+		key = b.Key(err)
+	} else {
+		key = metaString(err, MetaBudgetKey)
+	}
+	if key != "" {
+		b.Record(key, currentTime())
+	}
+}
+
+// Record counts an error of key seen at t.
+func (b *Budget) Record(key string, t time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.seen == nil {
+		b.seen = make(map[string][]time.Time)
+	}
+	if _, ok := b.seen[key]; !ok && len(b.seen) >= maxBudgetKeys {
+		for k := range b.seen {
+			if len(b.prune(k, t)) == 0 {
+				delete(b.seen, k)
+			}
+		}
+	}
+	seen := append(b.prune(key, t), t)
+	// Only the last Limit errors matter to the remaining budget.
+	if limit := b.limit(); len(seen) > limit {
+		seen = append(seen[:0], seen[len(seen)-limit:]...)
+	}
+	b.seen[key] = seen
+}
+
+// Remaining returns the number of errors key may still produce in
+// the window.
+func (b *Budget) Remaining(key string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.limit() - len(b.prune(key, currentTime()))
+}
+
+// Reset forgets the errors of key.
+func (b *Budget) Reset(key string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.seen, key)
+}
+
+// Check returns nil if key has budget left. Otherwise it returns a
+// retryable MAXIMUMATTEMPTS Error for op, annotated with the budget
+// and retryable once the oldest error of the window expires.
+func (b *Budget) Check(key, op string) error {
+	now := currentTime()
+	b.mu.Lock()
+	seen := b.prune(key, now)
+	limit := b.limit()
+	var retryAfter time.Duration
+	if len(seen) >= limit {
+		retryAfter = seen[0].Add(b.Window).Sub(now)
+	}
+	b.mu.Unlock()
+	if len(seen) < limit {
+		return nil
+	}
+	e := buildError(1, nil, "error budget exhausted", MAXIMUMATTEMPTS, op)
+	e.Retryable = true
+	e.WithField(MetaBudgetKey, key)
+	e.WithField(MetaBudgetLimit, limit)
+	e.WithField(MetaBudgetRemaining, 0)
+	e.WithRetryAfter(retryAfter)
+	notify(e)
+	return e
+}
+
+// Annotate records the budget and the remaining budget of key on
+// err, for the Responder to send them as headers, and returns err
+// for chaining.
+func (b *Budget) Annotate(err *Error, key string) *Error {
+	if err == nil {
+		return nil
+	}
+	err.WithField(MetaBudgetLimit, b.limit())
+	return err.WithField(MetaBudgetRemaining, b.Remaining(key))
+}
+
+func (b *Budget) limit() int {
+	if b.Limit < 1 {
+		return 1
+	}
+	return b.Limit
+}
+
+// prune drops the errors of key seen before the window.
+func (b *Budget) prune(key string, now time.Time) []time.Time {
+	seen := b.seen[key]
+	i := 0
+	for i < len(seen) && now.Sub(seen[i]) >= b.Window {
+		i++
+	}
+	if i > 0 {
+		seen = seen[i:]
+		b.seen[key] = seen
+	}
+	return seen
+}
@@ -0,0 +1,124 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+)
+
+// Builder builds an Error field by field:
+//
+//	return errors.Build().
+//		Code(errors.CONFLICT).
+//		Op("repo.Insert").
+//		Msg("duplicate user").
+//		Meta("email", email).
+//		Wrap(err)
+//
+// The stack is captured when Wrap or Err is called.
+type Builder struct {
+	code      string
+	op        string
+	message   string
+	severity  Severity
+	retryable bool
+	ctx       context.Context
+	metadata  map[string]any
+	noNotify  bool
+}
+
+// Build returns a Builder for an Error with the DefaultCode.
+func Build() *Builder {
+	return &Builder{code: DefaultCode}
+}
+
+// Code sets the error code.
+func (b *Builder) Code(code string) *Builder {
+	b.code = code
+	return b
+}
+
+// Op sets the operation.
+func (b *Builder) Op(op string) *Builder {
+	b.op = op
+	return b
+}
+
+// Msg sets the message.
+func (b *Builder) Msg(message string) *Builder {
+	b.message = message
+	return b
+}
+
+// Msgf sets the message from a format and its arguments.
+func (b *Builder) Msgf(format string, args ...any) *Builder {
+	b.message = fmt.Sprintf(format, args...)
+	return b
+}
+
+// Meta adds a metadata entry.
+func (b *Builder) Meta(key string, value any) *Builder {
+	if b.metadata == nil {
+		b.metadata = make(map[string]any)
+	}
+	b.metadata[key] = value
+	return b
+}
+
+// Severity sets the severity.
+func (b *Builder) Severity(s Severity) *Builder {
+	b.severity = s
+	return b
+}
+
+// Retryable marks the error as retryable.
+func (b *Builder) Retryable() *Builder {
+	b.retryable = true
+	return b
+}
+
+// Context sets the context of the error.
+func (b *Builder) Context(ctx context.Context) *Builder {
+	b.ctx = ctx
+	return b
+}
+
+// Silent disables the DefaultErrorCallbackHandler for the error.
+func (b *Builder) Silent() *Builder {
+	b.noNotify = true
+	return b
+}
+
+// Wrap returns the Error with err as its cause, or nil if err is
+// nil.
+func (b *Builder) Wrap(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	return b.build(err)
+}
+
+// Err returns the Error without a cause.
+func (b *Builder) Err() *Error {
+	return b.build(nil)
+}
+
+func (b *Builder) build(err error) *Error {
+	op := b.op
+	if b.ctx != nil {
+		op = contextOp(b.ctx, op)
+	}
+	e := buildError(2, err, b.message, b.code, op)
+	e.Context = b.ctx
+	e.Severity = b.severity
+	e.Retryable = b.retryable
+	if b.metadata != nil {
+		e.Metadata = make(map[string]any, len(b.metadata))
+		for k, v := range b.metadata {
+			e.Metadata[k] = v
+		}
+	}
+	if !b.noNotify {
+		notify(e)
+	}
+	return e
+}
@@ -0,0 +1,67 @@
+package errors
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Caller is the location at which an error was created, split in
+// structured fields.
+type Caller struct {
+	Package  string `json:"package,omitempty"`
+	Function string `json:"function,omitempty"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+}
+
+// IsZero reports whether the location is unknown.
+func (c Caller) IsZero() bool {
+	return c == Caller{}
+}
+
+// Caller returns the location at which the error was created. For
+// errors decoded without one, the file and line are parsed from
+// the file line.
+func (e *Error) Caller() Caller {
+	if e == nil {
+		return Caller{}
+	}
+	if e.caller.IsZero() && e.fileLine != "" {
+		return callerFromFileLine(e.fileLine)
+	}
+	return e.caller
+}
+
+// newCaller returns the Caller of the frame at pc.
+func newCaller(pc uintptr, file string, line int) Caller {
+	c := Caller{File: file, Line: line}
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		c.Package, c.Function = splitFuncName(fn.Name())
+	}
+	return c
+}
+
+// splitFuncName splits a fully qualified function name, such as
+// "github.com/oarkflow/errors.(*Error).Error", into its package
+// path and function name.
+func splitFuncName(name string) (pkg, fn string) {
+	slash := strings.LastIndexByte(name, '/') + 1
+	dot := strings.IndexByte(name[slash:], '.')
+	if dot < 0 {
+		return "", name
+	}
+	return name[:slash+dot], name[slash+dot+1:]
+}
+
+func callerFromFileLine(fileLine string) Caller {
+	i := strings.LastIndexByte(fileLine, ':')
+	if i < 0 {
+		return Caller{File: fileLine}
+	}
+	line, err := strconv.Atoi(fileLine[i+1:])
+	if err != nil {
+		return Caller{File: fileLine}
+	}
+	return Caller{File: fileLine[:i], Line: line}
+}
@@ -0,0 +1,34 @@
+package errors_test
+
+import (
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/oarkflow/errors"
+)
+
+func TestCallerIsCallSite(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *errors.Error
+	}{
+		{"Wrap", errors.Wrap(io.EOF, "read", "file.Read").(*errors.Error)},
+		{"ErrorF", errors.ErrorF(io.EOF, "file.Read", "read %d", false, 1)},
+		{"NewE", errors.NewE(io.EOF, "read", "file.Read", false)},
+		{"NewInternal", errors.NewInternal(io.EOF, "read", "file.Read", false)},
+	}
+	for _, tt := range tests {
+		c := tt.err.Caller()
+		if filepath.Base(c.File) != "caller_test.go" {
+			t.Errorf("%s: caller file = %s, want caller_test.go", tt.name, c.File)
+		}
+		if c.Package != "github.com/oarkflow/errors_test" {
+			t.Errorf("%s: caller package = %s", tt.name, c.Package)
+		}
+		if !strings.HasPrefix(filepath.Base(tt.err.FileLine()), "caller_test.go:") {
+			t.Errorf("%s: file line = %s", tt.name, tt.err.FileLine())
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package errors
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// CanonicalJSON returns the deterministic JSON encoding of the
+// error, see MarshalOptions.Deterministic, with the object keys
+// sorted and no insignificant space, so that errors with equal
+// content always encode to the same bytes, wherever and however
+// often they occurred. Numbers are kept as written, so integers
+// beyond the precision of a float64 still encode differently. An
+// error decoded from its canonical or deterministic encoding has
+// the same canonical encoding as long as its numbers fit a float64,
+// the type decoded metadata numbers have.
+func (e *Error) CanonicalJSON() ([]byte, error) {
+	b, err := e.MarshalJSONWithOptions(MarshalOptions{Deterministic: true})
+	if err != nil {
+		return nil, err
+	}
+	// Numbers are decoded as json.Number, which encodes verbatim,
+	// and encoding/json writes the keys of maps sorted.
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v any
+	if err = dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of the canonical JSON of
+// the error under key, e.g. to hand the error to a client in a
+// signed callback.
+func (e *Error) Sign(key []byte) (string, error) {
+	b, err := e.CanonicalJSON()
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(b)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// Verify reports whether signature, as returned by Sign, is the
+// signature of the error under key, i.e. the error came back
+// unmodified.
+func (e *Error) Verify(key []byte, signature string) bool {
+	want, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	b, err := e.CanonicalJSON()
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(b)
+	return hmac.Equal(mac.Sum(nil), want)
+}
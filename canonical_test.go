@@ -0,0 +1,79 @@
+package errors_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/oarkflow/errors"
+)
+
+var signKey = []byte("secret key")
+
+func TestCanonicalJSONContentOnly(t *testing.T) {
+	e := errors.NewNotFound(errors.NewInvalid(nil, "bad id", "parse"), "user not found", "users.get").
+		WithField("user", "42")
+	before, err := e.Sign(signKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.ID()
+	e.Touch()
+	if after, _ := e.Sign(signKey); after != before {
+		t.Error("signature changed after ID and Touch")
+	}
+	b, err := e.CanonicalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(b, &fields); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"id", "file_line", "caller", "occurrences", "first_seen", "last_seen", errors.StackFieldName} {
+		if v, ok := fields[key]; ok && v != "" {
+			t.Errorf("canonical JSON holds %s %v: %s", key, v, b)
+		}
+	}
+	if strings.Contains(string(b), "canonical_test.go") {
+		t.Errorf("canonical JSON holds a location: %s", b)
+	}
+}
+
+func TestCanonicalJSONRoundTrip(t *testing.T) {
+	e := errors.NewNotFound(errors.NewInvalid(nil, "bad id", "parse"), "user not found", "users.get").
+		WithField("attempts", 3)
+	signature, err := e.Sign(signKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := e.CanonicalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded errors.Error
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.Verify(signKey, signature) {
+		t.Errorf("decoded error does not verify: %s", b)
+	}
+	decoded.Message = "changed"
+	if decoded.Verify(signKey, signature) {
+		t.Error("modified error verifies")
+	}
+}
+
+func TestSignLargeIntegers(t *testing.T) {
+	a, err := errors.NewNotFound(nil, "no order", "orders.Get").WithField("order", int64(1<<60)).Sign(signKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := errors.NewNotFound(nil, "no order", "orders.Get").WithField("order", int64(1<<60+1)).Sign(signKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a == b {
+		t.Error("errors differing by an int64 beyond float64 precision sign the same")
+	}
+}
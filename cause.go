@@ -0,0 +1,98 @@
+package errors
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Metadata keys set for the causes registered with
+// CaptureCauseFields.
+const (
+	MetaCause     = "cause"
+	MetaCauseType = "cause_type"
+)
+
+var (
+	causeTypesMu sync.RWMutex
+	causeTypes   = make(map[reflect.Type]struct{})
+)
+
+// CaptureCauseFields opts the type of sample in: when an Error is
+// created wrapping an error of this type, anywhere in its chain,
+// the exported fields of that error are stored in the metadata
+// under MetaCause and its type under MetaCauseType. The
+// structured data of third-party errors, such as *pq.Error, then
+// survives JSON serialization:
+//
+//	errors.CaptureCauseFields(&pq.Error{})
+func CaptureCauseFields(sample error) {
+	if sample == nil {
+		return
+	}
+	causeTypesMu.Lock()
+	defer causeTypesMu.Unlock()
+	causeTypes[reflect.TypeOf(sample)] = struct{}{}
+}
+
+// CauseFields returns the exported fields of err, a struct or a
+// pointer to a struct, keeping only the values which can be
+// serialized. It returns nil for other errors.
+func CauseFields(err error) map[string]any {
+	v := reflect.ValueOf(err)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	t := v.Type()
+	fields := make(map[string]any, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() || !serializable(f.Type) {
+			continue
+		}
+		fv := v.Field(i)
+		if fv.IsZero() {
+			continue
+		}
+		fields[f.Name] = fv.Interface()
+	}
+	return fields
+}
+
+func serializable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer, reflect.Complex64, reflect.Complex128:
+		return false
+	}
+	return true
+}
+
+// captureCause records the fields of the first registered cause
+// found in the chain of err, from the outermost error.
+func (e *Error) captureCause(err error) {
+	causeTypesMu.RLock()
+	n := len(causeTypes)
+	causeTypesMu.RUnlock()
+	if n == 0 {
+		return
+	}
+	for i, cur := 0, err; cur != nil && i < maxChainLength; i, cur = i+1, Unwrap(cur) {
+		causeTypesMu.RLock()
+		_, ok := causeTypes[reflect.TypeOf(cur)]
+		causeTypesMu.RUnlock()
+		if !ok {
+			continue
+		}
+		if e.Metadata == nil {
+			e.Metadata = make(map[string]any)
+		}
+		e.Metadata[MetaCause] = CauseFields(cur)
+		e.Metadata[MetaCauseType] = reflect.TypeOf(cur).String()
+		return
+	}
+}
@@ -0,0 +1,44 @@
+package errors
+
+// Chain returns e followed by the Errors it wraps, outermost first,
+// as found with As along the causes. The walk stops at the first
+// Error seen twice, so that a chain made cyclic by assigning Err
+// directly is returned once. A nil error returns nil.
+func (e *Error) Chain() []*Error {
+	return chainOf(e)
+}
+
+// Causes returns the errors wrapped by e, its cause first, as
+// returned by successive calls to Unwrap. Like Chain, it stops at
+// the first Error seen twice.
+func (e *Error) Causes() []error {
+	if e == nil {
+		return nil
+	}
+	var causes []error
+	seen := map[*Error]bool{e: true}
+	cur := e.Err
+	for i := 0; cur != nil && i < maxChainLength; i++ {
+		if w, ok := cur.(*Error); ok {
+			if w == nil || seen[w] {
+				break
+			}
+			seen[w] = true
+		}
+		causes = append(causes, cur)
+		cur = Unwrap(cur)
+	}
+	return causes
+}
+
+// chainOf returns the Errors of the chain of err, outermost first.
+func chainOf(err error) []*Error {
+	var chain []*Error
+	seen := make(map[*Error]bool)
+	var e *Error
+	for cur := err; As(cur, &e) && e != nil && !seen[e] && len(chain) < maxChainLength; cur = e.Err {
+		seen[e] = true
+		chain = append(chain, e)
+	}
+	return chain
+}
@@ -0,0 +1,50 @@
+package errors_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/oarkflow/errors"
+)
+
+// chainWalkers calls every public function walking the chain of
+// err.
+var chainWalkers = map[string]func(err error){
+	"SeverityOf":       func(err error) { errors.SeverityOf(err) },
+	"Classify":         func(err error) { errors.Classify(err) },
+	"IsRetryable":      func(err error) { errors.IsRetryable(err) },
+	"RetryAfter":       func(err error) { errors.RetryAfter(err) },
+	"Violations":       func(err error) { errors.Violations(err) },
+	"RequestSnapshot":  func(err error) { errors.RequestSnapshot(err) },
+	"ResponseSnapshot": func(err error) { errors.ResponseSnapshot(err) },
+	"Ignore":           func(err error) { errors.Ignore(err, errors.NOTFOUND) },
+	"Code":             func(err error) { errors.Code(err) },
+	"Message":          func(err error) { errors.Message(err) },
+	"UserMessage":      func(err error) { errors.UserMessage(err) },
+	"AllMeta":          func(err error) { errors.AllMeta(err) },
+}
+
+func TestChainWalkersSelfWrapped(t *testing.T) {
+	e := errors.NewInternal(nil, "failed", "op", true)
+	e.Err = e
+	walkers := map[string]func(err error){
+		"LogLine":   func(error) { _ = e.LogLine() },
+		"Severity":  func(error) { e.View().Severity() },
+		"Retryable": func(error) { e.View().Retryable() },
+	}
+	for name, f := range chainWalkers {
+		walkers[name] = f
+	}
+	for name, f := range walkers {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			f(e)
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s does not return on a self-wrapped error", name)
+		}
+	}
+}
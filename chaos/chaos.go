@@ -0,0 +1,259 @@
+// Package chaos injects simulated errors into code paths, to test
+// resilience and retry behaviour end to end:
+//
+//	if err := chaos.Maybe("payments.charge"); err != nil {
+//		return err
+//	}
+//
+// Faults are configured per operation at runtime, from the
+// ERRORS_CHAOS environment variable, with Set, or through the HTTP
+// Handler. Nothing is injected until the injector is enabled.
+package chaos
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oarkflow/errors"
+)
+
+// EnvVar is the environment variable configuring the Default
+// injector, as a list of faults separated by semicolons, such as
+// "payments.charge=unavailable:0.1;db.query=timeout:0.5". A fault
+// is written op=code:probability. Setting it enables the injector.
+const EnvVar = "ERRORS_CHAOS"
+
+// MetaInjected is the metadata key marking the injected errors.
+const MetaInjected = "chaos"
+
+// Fault describes the error injected into an operation.
+type Fault struct {
+	// Code is the code of the injected errors, INTERNAL if empty.
+	Code string `json:"code"`
+	// Message is the message of the injected errors.
+	Message string `json:"message,omitempty"`
+	// Probability is the chance, from 0 to 1, that a call fails.
+	Probability float64 `json:"probability"`
+	// Retryable marks the injected errors as retryable.
+	Retryable bool `json:"retryable,omitempty"`
+	// Latency delays every call to the operation, failing or not.
+	Latency time.Duration `json:"latency,omitempty"`
+}
+
+// Injector holds the faults of the operations.
+type Injector struct {
+	mu      sync.RWMutex
+	enabled bool
+	faults  map[string]Fault
+	rand    *rand.Rand
+}
+
+// Default is the injector used by the package functions. It is
+// configured from EnvVar.
+var Default = NewInjector()
+
+func init() {
+	if v := os.Getenv(EnvVar); v != "" {
+		if err := Default.Parse(v); err == nil {
+			Default.Enable()
+		}
+	}
+}
+
+// NewInjector returns a disabled Injector without faults.
+func NewInjector() *Injector {
+	return &Injector{
+		faults: make(map[string]Fault),
+		rand:   rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Maybe returns the error of the fault set for op with the
+// Default injector, or nil.
+func Maybe(op string) error {
+	return Default.maybe(1, op)
+}
+
+// Set sets the fault of op in the Default injector.
+func Set(op string, f Fault) {
+	Default.Set(op, f)
+}
+
+// Enable enables the Default injector.
+func Enable() {
+	Default.Enable()
+}
+
+// Disable disables the Default injector, keeping its faults.
+func Disable() {
+	Default.Disable()
+}
+
+// Handler returns the HTTP handler controlling the Default
+// injector, see Injector.Handler.
+func Handler() http.Handler {
+	return Default.Handler()
+}
+
+// Maybe returns an error of the fault set for op with its
+// probability, after waiting for its latency. It returns nil when
+// the injector is disabled or op has no fault.
+func (i *Injector) Maybe(op string) error {
+	return i.maybe(1, op)
+}
+
+func (i *Injector) maybe(skip int, op string) error {
+	i.mu.RLock()
+	f, ok := i.faults[op]
+	enabled := i.enabled
+	i.mu.RUnlock()
+	if !enabled || !ok {
+		return nil
+	}
+	if f.Latency > 0 {
+		time.Sleep(f.Latency)
+	}
+	i.mu.Lock()
+	roll := i.rand.Float64()
+	i.mu.Unlock()
+	if roll >= f.Probability {
+		return nil
+	}
+	code := f.Code
+	if code == "" {
+		code = errors.INTERNAL
+	}
+	message := f.Message
+	if message == "" {
+		message = "chaos: injected " + code + " error"
+	}
+	e := errors.NewSkip(skip+1, nil, message, code, op)
+	e.Retryable = f.Retryable
+	e.WithField(MetaInjected, true)
+	return e
+}
+
+// Set sets the fault of op.
+func (i *Injector) Set(op string, f Fault) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.faults[op] = f
+}
+
+// Remove removes the fault of op.
+func (i *Injector) Remove(op string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	delete(i.faults, op)
+}
+
+// Faults returns a copy of the faults, keyed by operation.
+func (i *Injector) Faults() map[string]Fault {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	out := make(map[string]Fault, len(i.faults))
+	for op, f := range i.faults {
+		out[op] = f
+	}
+	return out
+}
+
+// Enable starts injecting the faults.
+func (i *Injector) Enable() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.enabled = true
+}
+
+// Disable stops injecting the faults, which are kept.
+func (i *Injector) Disable() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.enabled = false
+}
+
+// Enabled reports whether the faults are injected.
+func (i *Injector) Enabled() bool {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.enabled
+}
+
+// Parse sets the faults written in the EnvVar format.
+func (i *Injector) Parse(s string) error {
+	faults := make(map[string]Fault)
+	for _, item := range strings.Split(s, ";") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		op, spec, ok := strings.Cut(item, "=")
+		if !ok || op == "" {
+			return fmt.Errorf("chaos: invalid fault %q", item)
+		}
+		code, prob, _ := strings.Cut(spec, ":")
+		f := Fault{Code: code, Probability: 1}
+		if prob != "" {
+			p, err := strconv.ParseFloat(prob, 64)
+			if err != nil {
+				return fmt.Errorf("chaos: invalid probability in %q", item)
+			}
+			f.Probability = p
+		}
+		faults[op] = f
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for op, f := range faults {
+		i.faults[op] = f
+	}
+	return nil
+}
+
+// state is the JSON representation served by Handler.
+type state struct {
+	Enabled bool             `json:"enabled"`
+	Faults  map[string]Fault `json:"faults"`
+}
+
+// Handler returns an HTTP handler controlling the injector. GET
+// returns the state, PUT replaces it with the JSON body, of the
+// same shape, and DELETE disables the injector and removes the
+// faults.
+func (i *Injector) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var s state
+			if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+				errors.WriteError(w, r, errors.FromJSONError(err))
+				return
+			}
+			i.mu.Lock()
+			i.enabled = s.Enabled
+			i.faults = make(map[string]Fault, len(s.Faults))
+			for op, f := range s.Faults {
+				i.faults[op] = f
+			}
+			i.mu.Unlock()
+		case http.MethodDelete:
+			i.mu.Lock()
+			i.enabled = false
+			i.faults = make(map[string]Fault)
+			i.mu.Unlock()
+		default:
+			w.Header().Set("Allow", "GET, PUT, DELETE")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(state{Enabled: i.Enabled(), Faults: i.Faults()})
+	})
+}
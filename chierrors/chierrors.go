@@ -0,0 +1,37 @@
+// Package chierrors names the operation of the errors produced
+// by go-chi/chi handlers after the matched route.
+package chierrors
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/oarkflow/errors"
+)
+
+// Middleware sets the default operation of the request context to
+// the method and route pattern matched by chi, such as
+// "GET /users/{id}". The pattern is read when an error needs it,
+// so the middleware can be mounted before the routes are matched.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		ctx = errors.ContextWithOpFunc(ctx, func() string {
+			return Op(r.Method, chi.RouteContext(ctx))
+		})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Op returns the operation of a request routed by chi. It is
+// empty if no route matched.
+func Op(method string, rctx *chi.Context) string {
+	if rctx == nil {
+		return ""
+	}
+	pattern := rctx.RoutePattern()
+	if pattern == "" {
+		return ""
+	}
+	return method + " " + pattern
+}
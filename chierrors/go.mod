@@ -0,0 +1,10 @@
+module github.com/oarkflow/errors/chierrors
+
+go 1.20
+
+require (
+	github.com/go-chi/chi/v5 v5.0.10
+	github.com/oarkflow/errors v0.0.0
+)
+
+replace github.com/oarkflow/errors => ../
@@ -0,0 +1,160 @@
+package errors
+
+import (
+	"context"
+	"net"
+	"os"
+	"sync"
+	"syscall"
+)
+
+// Classifier inspects an error without an application code and
+// returns the code to assign to it, whether the failure is worth
+// retrying, and ok set to true if it recognised the error.
+type Classifier func(err error) (code string, retryable bool, ok bool)
+
+var (
+	classifiersMu sync.RWMutex
+	classifiers   = []Classifier{ClassifyNet, ClassifySyscall}
+)
+
+// RegisterClassifier adds a classifier consulted by Classify
+// before the builtin ones.
+func RegisterClassifier(c Classifier) {
+	classifiersMu.Lock()
+	defer classifiersMu.Unlock()
+	classifiers = append([]Classifier{c}, classifiers...)
+}
+
+// Classify returns the first Error with a code found in the
+// chain of err. Otherwise, err is wrapped in an Error whose code
+// and retryability are given by the first matching translation,
+// see Translate, then by the first registered classifier
+// recognising it, or UNKNOWN if none does. If err is nil,
+// Classify returns nil.
+func Classify(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	for _, e := range chainOf(err) {
+		if e.Code != "" {
+			return e
+		}
+	}
+	e := classified(1, err, "")
+	notify(e)
+	return e
+}
+
+// classified wraps err, which has no application code, in an
+// Error whose code is found as described by Classify.
+func classified(skip int, err error, op string) *Error {
+	if t, ok := translate(err); ok {
+		e := buildError(skip+1, err, "", t.code, op)
+		t.apply(e)
+		return e
+	}
+	code, retryable := UNKNOWN, false
+	classifiersMu.RLock()
+	for _, c := range classifiers {
+		if cc, r, ok := c(err); ok {
+			code, retryable = cc, r
+			break
+		}
+	}
+	classifiersMu.RUnlock()
+	e := buildError(skip+1, err, "", code, op)
+	e.Retryable = retryable
+	return e
+}
+
+// IsRetryable reports whether an Error in the chain of err is
+// marked as retryable. A retry delay alone, see RetryAfter, only
+// tells how long to wait.
+func IsRetryable(err error) bool {
+	for _, e := range chainOf(err) {
+		if e.Retryable {
+			return true
+		}
+	}
+	return false
+}
+
+// RetryAfterConnect makes ClassifyNet and ClassifySyscall mark the
+// network failures occurring after the connection was established
+// as retryable. It is off by default, as the request may have been
+// processed, and retrying it may duplicate a write.
+var RetryAfterConnect = false
+
+// ClassifyNet is the builtin Classifier for network errors. DNS
+// lookups and dials which failed, such as refused connections, are
+// UNAVAILABLE, or TIMEOUT, and retryable, except for unknown hosts.
+// Failures reading or writing an established connection, such as a
+// connection reset by the peer, are only retryable if
+// RetryAfterConnect is set.
+func ClassifyNet(err error) (code string, retryable bool, ok bool) {
+	var dnsErr *net.DNSError
+	if As(err, &dnsErr) {
+		if dnsErr.IsTimeout {
+			return TIMEOUT, true, true
+		}
+		return UNAVAILABLE, !dnsErr.IsNotFound, true
+	}
+	var opErr *net.OpError
+	if !As(err, &opErr) {
+		return "", false, false
+	}
+	code = UNAVAILABLE
+	if opErr.Timeout() {
+		code = TIMEOUT
+	}
+	if opErr.Op == "dial" {
+		return code, true, true
+	}
+	return code, RetryAfterConnect, true
+}
+
+// ClassifySyscall is the builtin Classifier for operating system
+// errors, such as syscall.Errno, possibly wrapped in
+// os.PathError, os.LinkError or os.SyscallError, and for
+// deadline errors. Like for ClassifyNet, connections reset or
+// aborted are only retryable while dialing, or if
+// RetryAfterConnect is set.
+func ClassifySyscall(err error) (code string, retryable bool, ok bool) {
+	switch {
+	case Is(err, context.DeadlineExceeded), Is(err, os.ErrDeadlineExceeded):
+		return TIMEOUT, true, true
+	case Is(err, os.ErrNotExist):
+		return NOTFOUND, false, true
+	case Is(err, os.ErrPermission):
+		return FORBIDDEN, false, true
+	case Is(err, os.ErrExist):
+		return CONFLICT, false, true
+	}
+	var errno syscall.Errno
+	if !As(err, &errno) {
+		return "", false, false
+	}
+	switch errno {
+	case syscall.ETIMEDOUT:
+		return TIMEOUT, true, true
+	case syscall.ENOSPC, syscall.EMFILE, syscall.ENFILE, syscall.ENOMEM:
+		return RESOURCEEXHAUSTED, false, true
+	case syscall.ECONNREFUSED, syscall.EAGAIN:
+		return UNAVAILABLE, true, true
+	case syscall.ECONNRESET, syscall.ECONNABORTED:
+		// The request may have been processed before the connection
+		// broke, see RetryAfterConnect.
+		var opErr *net.OpError
+		return UNAVAILABLE, RetryAfterConnect || As(err, &opErr) && opErr.Op == "dial", true
+	case syscall.EINVAL:
+		return INVALID, false, true
+	}
+	if errno.Timeout() {
+		return TIMEOUT, true, true
+	}
+	if errno.Temporary() {
+		return UNAVAILABLE, true, true
+	}
+	return "", false, false
+}
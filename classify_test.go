@@ -0,0 +1,36 @@
+package errors_test
+
+import (
+	"net"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/oarkflow/errors"
+)
+
+func TestClassifySyscallConnReset(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"bare reset", syscall.ECONNRESET, false},
+		{"bare aborted", os.NewSyscallError("read", syscall.ECONNABORTED), false},
+		{"read reset", &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}, false},
+		{"dial reset", &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNRESET)}, true},
+		{"refused", syscall.ECONNREFUSED, true},
+	}
+	for _, tt := range tests {
+		code, retryable, ok := errors.ClassifySyscall(tt.err)
+		if !ok || code != errors.UNAVAILABLE || retryable != tt.want {
+			t.Errorf("%s: ClassifySyscall = %q, %v, %v, want UNAVAILABLE, %v", tt.name, code, retryable, ok, tt.want)
+		}
+	}
+
+	errors.RetryAfterConnect = true
+	defer func() { errors.RetryAfterConnect = false }()
+	if _, retryable, _ := errors.ClassifySyscall(syscall.ECONNRESET); !retryable {
+		t.Error("bare reset not retryable with RetryAfterConnect")
+	}
+}
@@ -0,0 +1,58 @@
+package errors
+
+import (
+	"io"
+)
+
+// CaptureClose closes c and records a failure in *errp. It is
+// meant to be deferred by functions with a named error result:
+//
+//	func read(name string) (err error) {
+//		f, err := os.Open(name)
+//		if err != nil {
+//			return err
+//		}
+//		defer errors.CaptureClose(&err, f, "file.Read")
+//		...
+//	}
+//
+// See Defer for how the failure is recorded.
+func CaptureClose(errp *error, c io.Closer, op string) {
+	if c == nil {
+		return
+	}
+	capture(errp, c.Close(), "close failed", op)
+}
+
+// Defer calls f and records a failure in *errp, wrapped in an
+// Error with the DefaultCode for the operation. If *errp already
+// holds an error, both are kept in a Multi, the original first,
+// so that neither is dropped. A nil errp only reports the failure
+// to the error handler.
+func Defer(errp *error, f func() error, op string) {
+	if f == nil {
+		return
+	}
+	capture(errp, f(), "cleanup failed", op)
+}
+
+func capture(errp *error, err error, message, op string) {
+	if IsNil(err) {
+		return
+	}
+	e := buildError(2, err, message, DefaultCode, op)
+	notify(e)
+	if errp == nil {
+		return
+	}
+	if IsNil(*errp) {
+		*errp = e
+		return
+	}
+	switch prev := (*errp).(type) {
+	case *Multi:
+		prev.Append(e)
+	default:
+		*errp = &Multi{Errors: []error{prev, e}}
+	}
+}
@@ -0,0 +1,39 @@
+package errors_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/oarkflow/errors"
+)
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error { return f() }
+
+func TestCaptureCloseTypedNil(t *testing.T) {
+	var typedNil *errors.Error
+	err := func() (err error) {
+		err = typedNil
+		defer errors.CaptureClose(&err, closerFunc(func() error { return io.ErrClosedPipe }), "file.Close")
+		return typedNil
+	}()
+	var e *errors.Error
+	if !errors.As(err, &e) || e == nil {
+		t.Fatalf("err = %#v, want the close failure alone", err)
+	}
+	if _, ok := err.(*errors.Multi); ok {
+		t.Errorf("err = %#v, holds the typed nil in a Multi", err)
+	}
+
+	err = nil
+	errors.Defer(&err, func() error { return typedNil }, "cleanup")
+	if err != nil {
+		t.Errorf("Defer recorded a typed nil failure: %#v", err)
+	}
+}
+
+func TestCaptureNilErrp(t *testing.T) {
+	errors.Defer(nil, func() error { return io.ErrClosedPipe }, "cleanup")
+	errors.CaptureClose(nil, closerFunc(func() error { return nil }), "file.Close")
+}
@@ -0,0 +1,13 @@
+package errors
+
+import (
+	"time"
+
+	"github.com/oarkflow/errors/internal/hooks"
+)
+
+// currentTime returns the current time of the clock, which errtest
+// may replace.
+func currentTime() time.Time {
+	return hooks.Now()
+}
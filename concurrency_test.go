@@ -0,0 +1,53 @@
+package errors_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/oarkflow/errors"
+)
+
+// TestConcurrentUse mutates and renders one error from several
+// goroutines; run it with -race.
+func TestConcurrentUse(t *testing.T) {
+	e := errors.NewInternal(errors.New("connection reset"), "query failed", "db.query").
+		WithField("table", "users")
+
+	const n = 50
+	var wg sync.WaitGroup
+	wg.Add(4)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < n; i++ {
+			e.WithField(fmt.Sprintf("key%d", i), i)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < n; i++ {
+			e.WithNote(fmt.Sprintf("note %d", i))
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < n; i++ {
+			_ = e.Error()
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < n; i++ {
+			if _, err := json.Marshal(e); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	wg.Wait()
+
+	if got := len(e.Fields()); got != n+1 {
+		t.Errorf("got %d fields, want %d", got, n+1)
+	}
+}
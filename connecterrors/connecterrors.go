@@ -0,0 +1,74 @@
+// Package connecterrors converts application errors to and from
+// connect-go errors.
+package connecterrors
+
+import (
+	"encoding/json"
+
+	"connectrpc.com/connect"
+	"github.com/oarkflow/errors"
+	"github.com/oarkflow/errors/internal/rpccode"
+)
+
+// Metadata headers carrying the application error details.
+const (
+	HeaderCode      = "X-Error-Code"
+	HeaderOperation = "X-Error-Operation"
+	HeaderMetadata  = "X-Error-Metadata"
+)
+
+// ToConnect converts err to a *connect.Error. The Connect code is
+// derived from the application code, which is carried along with
+// the operation and the JSON encoded metadata in the error meta
+// headers, after being shaped by errors.Outbound. Errors already
+// of type *connect.Error are returned unchanged, and nil returns
+// nil.
+func ToConnect(err error) *connect.Error {
+	if err == nil {
+		return nil
+	}
+	var ce *connect.Error
+	if errors.As(err, &ce) {
+		return ce
+	}
+	e := errors.Outbound(errors.From(err))
+	ce = connect.NewError(connect.Code(rpccode.FromCode(errors.Code(e))), errors.New(errors.Message(e)))
+	ce.Meta().Set(HeaderCode, errors.Code(e))
+	if e.Operation != "" {
+		ce.Meta().Set(HeaderOperation, e.Operation)
+	}
+	if metadata := e.Fields(); len(metadata) > 0 {
+		if b, mErr := json.Marshal(metadata); mErr == nil {
+			ce.Meta().Set(HeaderMetadata, string(b))
+		}
+	}
+	return ce
+}
+
+// FromConnect converts a *connect.Error found in the chain of err
+// to an Error, restoring the application code, operation and
+// metadata from the meta headers when present. Other errors are
+// promoted with errors.From, and nil returns nil.
+func FromConnect(err error) *errors.Error {
+	if err == nil {
+		return nil
+	}
+	var ce *connect.Error
+	if !errors.As(err, &ce) {
+		return errors.From(err)
+	}
+	code := ce.Meta().Get(HeaderCode)
+	if code == "" {
+		code = rpccode.ToCode(uint32(ce.Code()))
+	}
+	e := errors.NewSkip(1, ce, ce.Message(), code, ce.Meta().Get(HeaderOperation))
+	if raw := ce.Meta().Get(HeaderMetadata); raw != "" {
+		var metadata map[string]any
+		if json.Unmarshal([]byte(raw), &metadata) == nil {
+			for k, v := range metadata {
+				e.WithField(k, v)
+			}
+		}
+	}
+	return e
+}
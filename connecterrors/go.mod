@@ -0,0 +1,12 @@
+module github.com/oarkflow/errors/connecterrors
+
+go 1.20
+
+require (
+	connectrpc.com/connect v1.11.1
+	github.com/oarkflow/errors v0.0.0
+)
+
+require google.golang.org/protobuf v1.31.0 // indirect
+
+replace github.com/oarkflow/errors => ../
@@ -0,0 +1,71 @@
+package errors
+
+import (
+	"context"
+)
+
+// Contract declares the codes an operation may return, so
+// unexpected codes leaking out of a handler are detected.
+type Contract struct {
+	// Op is the operation the contract applies to.
+	Op string
+	// Codes are the allowed codes. Descendants of hierarchical
+	// codes are allowed too.
+	Codes []string
+	// Convert replaces errors with an unexpected code by an
+	// INTERNAL Error wrapping them.
+	Convert bool
+	// OnViolation, if set, is called with every error breaking
+	// the contract.
+	OnViolation func(err *Error)
+	// Reporter, if set, receives every error breaking the
+	// contract.
+	Reporter Reporter
+}
+
+// NewContract returns a Contract allowing op to return the codes.
+func NewContract(op string, codes ...string) *Contract {
+	return &Contract{Op: op, Codes: codes}
+}
+
+// Allows reports whether the code is allowed by the contract.
+func (c *Contract) Allows(code string) bool {
+	for _, allowed := range c.Codes {
+		if hasCodePrefix(code, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check returns nil if err is nil, including a nil *Error, and err
+// unchanged if its code is allowed.
+// Otherwise, the violation is passed to OnViolation and the
+// Reporter, and err is returned, or converted to an INTERNAL
+// Error when Convert is set.
+func (c *Contract) Check(err error) error {
+	if IsNil(err) {
+		return nil
+	}
+	code := Code(err)
+	if c.Allows(code) {
+		return err
+	}
+	violation := buildError(1, err, "unexpected error code "+code, INTERNAL, c.Op)
+	if c.OnViolation != nil {
+		c.OnViolation(violation)
+	}
+	if c.Reporter != nil {
+		ctx := context.Background()
+		var e *Error
+		if As(err, &e) && e.Context != nil {
+			ctx = e.Context
+		}
+		_ = c.Reporter.Report(ctx, violation)
+	}
+	if c.Convert {
+		notify(violation)
+		return violation
+	}
+	return err
+}
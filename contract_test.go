@@ -0,0 +1,18 @@
+package errors_test
+
+import (
+	"testing"
+
+	"github.com/oarkflow/errors"
+)
+
+func TestContractCheckNil(t *testing.T) {
+	c := errors.NewContract("users.get", errors.NOTFOUND)
+	var typedNil *errors.Error
+	if err := c.Check(typedNil); err != nil {
+		t.Errorf("Check(typed nil) = %#v, want nil", err)
+	}
+	if err := c.Check(nil); err != nil {
+		t.Errorf("Check(nil) = %#v, want nil", err)
+	}
+}
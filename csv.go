@@ -0,0 +1,118 @@
+package errors
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSVFields are the columns written by WriteCSV and WriteTSV when no
+// field is given. The other columns are "id", "severity",
+// "occurrences", "first_seen", "last_seen" and "meta.<key>", the
+// metadata value of key.
+var CSVFields = []string{"time", "code", "op", "message", "fingerprint"}
+
+// WriteCSV writes a header and one row per occurrence recorded by
+// DefaultStats to w as CSV, oldest first, with the selected fields
+// as columns. The "time" column is the time of the occurrence. The
+// "id" column is empty for the errors which were not assigned an
+// ID.
+func WriteCSV(w io.Writer, fields ...string) error {
+	return DefaultStats.WriteCSV(w, fields...)
+}
+
+// WriteTSV is like WriteCSV, with the columns separated by tabs.
+func WriteTSV(w io.Writer, fields ...string) error {
+	return DefaultStats.WriteTSV(w, fields...)
+}
+
+// WriteCSV is like the WriteCSV function, for the occurrences
+// recorded by s.
+func (s *StatsCollector) WriteCSV(w io.Writer, fields ...string) error {
+	return writeRecords(w, ',', s.Occurrences(), fields)
+}
+
+// WriteTSV is like the WriteTSV function, for the occurrences
+// recorded by s.
+func (s *StatsCollector) WriteTSV(w io.Writer, fields ...string) error {
+	return writeRecords(w, '\t', s.Occurrences(), fields)
+}
+
+func writeRecords(w io.Writer, comma rune, occurrences []Occurrence, fields []string) error {
+	if len(fields) == 0 {
+		fields = CSVFields
+	}
+	for _, f := range fields {
+		if !validCSVField(f) {
+			return fmt.Errorf("errors: unknown CSV field %q", f)
+		}
+	}
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	if err := cw.Write(fields); err != nil {
+		return err
+	}
+	row := make([]string, len(fields))
+	for _, o := range occurrences {
+		if o.Error == nil {
+			continue
+		}
+		for i, f := range fields {
+			row[i] = csvValue(o, f)
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func validCSVField(f string) bool {
+	switch f {
+	case "time", "code", "op", "message", "fingerprint", "id", "severity",
+		"occurrences", "first_seen", "last_seen":
+		return true
+	}
+	return strings.HasPrefix(f, "meta.") && len(f) > len("meta.")
+}
+
+func csvValue(o Occurrence, f string) string {
+	e := o.Error
+	switch f {
+	case "time":
+		return csvTime(o.Time)
+	case "last_seen":
+		return csvTime(e.LastSeen())
+	case "first_seen":
+		return csvTime(e.FirstSeen())
+	case "code":
+		return e.Code
+	case "op":
+		return e.Operation
+	case "message":
+		return e.Message
+	case "fingerprint":
+		return e.Fingerprint()
+	case "id":
+		return e.assignedID()
+	case "severity":
+		return e.Severity.String()
+	case "occurrences":
+		return strconv.FormatInt(e.Occurrences(), 10)
+	}
+	if v, ok := e.Meta(strings.TrimPrefix(f, "meta.")); ok && v != nil {
+		return fmt.Sprint(v)
+	}
+	return ""
+}
+
+func csvTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
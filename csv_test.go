@@ -0,0 +1,74 @@
+package errors_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/oarkflow/errors"
+	"github.com/oarkflow/errors/errtest"
+)
+
+func TestStatsWriteCSV(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	now := at
+	errtest.SetClock(t, func() time.Time { return now })
+
+	s := errors.NewStatsCollector()
+	notFound := errors.NewNotFound(nil, "user not found", "users.get", true)
+	id := notFound.ID()
+	s.Handler(notFound)
+	now = at.Add(time.Second)
+	s.Handler(errors.NewInvalid(nil, "bad id", "users.get", true))
+	now = at.Add(2 * time.Second)
+	s.Handler(notFound)
+
+	var buf bytes.Buffer
+	if err := s.WriteCSV(&buf, "time", "code", "id"); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"time", "code", "id"},
+		{"2024-05-01T12:00:00Z", "not_found", id},
+		{"2024-05-01T12:00:01Z", "invalid", ""},
+		{"2024-05-01T12:00:02Z", "not_found", id},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("got %d rows, want %d: %q", len(rows), len(want), rows)
+	}
+	for i := range want {
+		for j := range want[i] {
+			if rows[i][j] != want[i][j] {
+				t.Errorf("row %d column %d = %q, want %q", i, j, rows[i][j], want[i][j])
+			}
+		}
+	}
+	// Reading the ID column does not assign IDs.
+	var again bytes.Buffer
+	if err := s.WriteCSV(&again, "id"); err != nil {
+		t.Fatal(err)
+	}
+	if want := id + "\n\n" + id + "\n"; again.String() != "id\n"+want {
+		t.Errorf("second export = %q, want %q", again.String(), "id\n"+want)
+	}
+}
+
+func TestStatsRecentOccurrences(t *testing.T) {
+	prev := errors.RecentOccurrences
+	errors.RecentOccurrences = 2
+	t.Cleanup(func() { errors.RecentOccurrences = prev })
+
+	s := errors.NewStatsCollector()
+	for _, op := range []string{"a", "b", "c"} {
+		s.Handler(errors.NewInternal(nil, "failed", op, true))
+	}
+	got := s.Occurrences()
+	if len(got) != 2 || got[0].Error.Operation != "b" || got[1].Error.Operation != "c" {
+		t.Errorf("Occurrences() kept %d occurrences: %+v", len(got), got)
+	}
+}
@@ -0,0 +1,70 @@
+package errors
+
+// ErrCyclicWrap replaces, or is returned for, a cause whose chain
+// loops back on itself, such as after e.Err = e.
+var ErrCyclicWrap = New("errors: cyclic error chain")
+
+// MetaCyclic is the metadata key set to true on Errors created
+// with a cyclic cause, which is replaced by ErrCyclicWrap.
+const MetaCyclic = "cyclic_chain"
+
+// maxChainLength bounds the chains walked looking for cycles, to
+// catch errors whose Unwrap method returns themselves.
+const maxChainLength = 1 << 12
+
+// SetCause sets the cause of the error. It returns ErrCyclicWrap
+// and leaves the error unchanged if the chain of err contains the
+// error itself or loops on its own.
+func (e *Error) SetCause(err error) error {
+	if e == nil {
+		return ErrCyclicWrap
+	}
+	if chainContains(err, e) || cyclic(err) {
+		return ErrCyclicWrap
+	}
+	e.Err = err
+	return nil
+}
+
+// chainContains reports whether target is in the chain of err.
+func chainContains(err error, target *Error) bool {
+	cur := err
+	for i := 0; cur != nil && i < maxChainLength; i++ {
+		if e, ok := cur.(*Error); ok {
+			if e == target {
+				return true
+			}
+			if e == nil {
+				return false
+			}
+			cur = e.Err
+			continue
+		}
+		cur = Unwrap(cur)
+	}
+	return false
+}
+
+// cyclic reports whether the chain of err loops on itself.
+func cyclic(err error) bool {
+	seen := make(map[*Error]bool)
+	cur := err
+	for i := 0; cur != nil; i++ {
+		if i >= maxChainLength {
+			return true
+		}
+		if e, ok := cur.(*Error); ok {
+			if e == nil {
+				return false
+			}
+			if seen[e] {
+				return true
+			}
+			seen[e] = true
+			cur = e.Err
+			continue
+		}
+		cur = Unwrap(cur)
+	}
+	return false
+}
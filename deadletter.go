@@ -0,0 +1,97 @@
+package errors
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+)
+
+// MetaPayload is the metadata key holding the payload of the
+// failed work, persisted along with the error by dead-letter
+// sinks.
+const MetaPayload = "payload"
+
+// Sink persists errors which could not be processed, so that
+// they can be inspected or replayed later.
+type Sink interface {
+	Write(ctx context.Context, err *Error) error
+}
+
+// WriterSink is a Sink writing one JSON encoded error per line.
+type WriterSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriterSink returns a WriterSink writing to w.
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+// Write appends the error as a JSON line.
+func (s *WriterSink) Write(_ context.Context, err *Error) error {
+	if err == nil {
+		return nil
+	}
+	b, mErr := err.MarshalJSON()
+	if mErr != nil {
+		return mErr
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, wErr := s.w.Write(append(b, '\n'))
+	return wErr
+}
+
+// FileSink is a WriterSink appending to a file.
+type FileSink struct {
+	*WriterSink
+	f *os.File
+}
+
+// NewFileSink returns a FileSink appending to the file at path,
+// which is created if needed.
+func NewFileSink(path string) (*FileSink, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSink{WriterSink: NewWriterSink(f), f: f}, nil
+}
+
+// Write appends the error to the file and syncs it to disk.
+func (s *FileSink) Write(ctx context.Context, err *Error) error {
+	if wErr := s.WriterSink.Write(ctx, err); wErr != nil {
+		return wErr
+	}
+	return s.f.Sync()
+}
+
+// Close closes the file.
+func (s *FileSink) Close() error {
+	return s.f.Close()
+}
+
+// Replay decodes the JSON lines written by a WriterSink and calls
+// fn with every error, stopping at the first failure.
+func Replay(r io.Reader, fn func(err *Error) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		e := &Error{}
+		if err := json.Unmarshal(line, e); err != nil {
+			return err
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
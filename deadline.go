@@ -0,0 +1,53 @@
+package errors
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// Metadata keys set by WrapCtx.
+const (
+	MetaDeadlineRemaining = "deadline_remaining"
+	MetaDeadlineExceeded  = "deadline_exceeded"
+)
+
+// WrapCtx is like Wrap for an operation running under ctx. If ctx
+// has a deadline, the time left, or that the deadline was
+// exceeded, is recorded in the metadata, and the code is TIMEOUT
+// when the deadline caused the failure. The operation is completed
+// from ctx as described by PushOp. If err is nil, WrapCtx returns a true
+// nil error.
+func WrapCtx(ctx context.Context, err error, message, op string) error {
+	if err == nil {
+		return nil
+	}
+	code := DefaultCode
+	exceeded := Is(err, context.DeadlineExceeded) || Is(err, os.ErrDeadlineExceeded)
+	if ctx != nil && Is(ctx.Err(), context.DeadlineExceeded) {
+		exceeded = true
+	}
+	if exceeded {
+		code = TIMEOUT
+	}
+	op = contextOp(ctx, op)
+	e := buildError(1, err, message, code, op)
+	e.Context = ctx
+	if ctx != nil {
+		if deadline, ok := ctx.Deadline(); ok {
+			if e.Metadata == nil {
+				e.Metadata = make(map[string]any)
+			}
+			if remaining := deadline.Sub(currentTime()); remaining > 0 && !exceeded {
+				e.Metadata[MetaDeadlineRemaining] = remaining.Round(time.Millisecond).String()
+			} else {
+				e.Metadata[MetaDeadlineExceeded] = true
+			}
+		}
+	}
+	if exceeded {
+		e.Retryable = true
+	}
+	notify(e)
+	return e
+}
@@ -0,0 +1,100 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// maxDedupKeys bounds the number of fingerprints a Dedup tracks.
+// Once it is reached, errors with a new fingerprint are sent
+// without being tracked until a window ends.
+const maxDedupKeys = 1024
+
+// Dedup is a Reporter decorator suppressing errors having the
+// same fingerprint within a window. The first error of a window
+// is sent right away; when the window ends, a summary reporting
+// the number of occurrences is sent if any were suppressed.
+// Summaries are reported with a background context. Call Flush
+// to send the pending summaries early and stop the timers.
+type Dedup struct {
+	next   Reporter
+	window time.Duration
+	mu     sync.Mutex
+	seen   map[string]*dedupEntry
+}
+
+type dedupEntry struct {
+	count int
+	err   *Error
+	timer *time.Timer
+}
+
+// NewDedup returns a Dedup reporting to next.
+func NewDedup(next Reporter, window time.Duration) *Dedup {
+	return &Dedup{next: next, window: window, seen: make(map[string]*dedupEntry)}
+}
+
+// Report sends the error to the next reporter unless one with
+// the same fingerprint has already been sent in the window.
+func (d *Dedup) Report(ctx context.Context, err *Error) error {
+	if err == nil {
+		return nil
+	}
+	fp := err.Fingerprint()
+	d.mu.Lock()
+	if entry, ok := d.seen[fp]; ok {
+		entry.count++
+		d.mu.Unlock()
+		return nil
+	}
+	if len(d.seen) < maxDedupKeys {
+		entry := &dedupEntry{count: 1, err: err}
+		entry.timer = time.AfterFunc(d.window, func() { d.expire(fp, entry) })
+		d.seen[fp] = entry
+	}
+	d.mu.Unlock()
+	return d.next.Report(ctx, err)
+}
+
+// Flush sends the summaries of all suppressed errors and
+// resets the windows.
+func (d *Dedup) Flush(ctx context.Context) error {
+	d.mu.Lock()
+	var summaries []*Error
+	for fp, entry := range d.seen {
+		entry.timer.Stop()
+		if entry.count > 1 {
+			summaries = append(summaries, d.summary(entry))
+		}
+		delete(d.seen, fp)
+	}
+	d.mu.Unlock()
+	var first error
+	for _, e := range summaries {
+		if err := d.next.Report(ctx, e); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// expire ends the window of the entry and sends its summary.
+func (d *Dedup) expire(fp string, entry *dedupEntry) {
+	d.mu.Lock()
+	if d.seen[fp] != entry {
+		d.mu.Unlock()
+		return
+	}
+	delete(d.seen, fp)
+	count := entry.count
+	d.mu.Unlock()
+	if count > 1 {
+		_ = d.next.Report(context.Background(), d.summary(entry))
+	}
+}
+
+func (d *Dedup) summary(entry *dedupEntry) *Error {
+	return entry.err.WithMessage(fmt.Sprintf("%s (occurred %d times in %s)", entry.err.Message, entry.count, d.window))
+}
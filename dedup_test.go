@@ -0,0 +1,41 @@
+package errors_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/oarkflow/errors"
+)
+
+func TestDedupSummaryAtWindowEnd(t *testing.T) {
+	sent := make(chan *errors.Error, 4)
+	d := errors.NewDedup(errors.ReporterFunc(func(_ context.Context, err *errors.Error) error {
+		sent <- err
+		return nil
+	}), 20*time.Millisecond)
+
+	for i := 0; i < 3; i++ {
+		_ = d.Report(context.Background(), errors.NewE(nil, "db down", "db.Query", false))
+	}
+	if e := <-sent; strings.Contains(e.Message, "occurred") {
+		t.Fatalf("first report = %q, want the error itself", e.Message)
+	}
+	select {
+	case e := <-sent:
+		if !strings.Contains(e.Message, "occurred 3 times") {
+			t.Errorf("summary = %q, want 3 occurrences", e.Message)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no summary sent after the window ended")
+	}
+	if err := d.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case e := <-sent:
+		t.Errorf("Flush after the window sent %q", e.Message)
+	default:
+	}
+}
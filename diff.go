@@ -0,0 +1,97 @@
+package errors
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Diff returns a readable field-by-field comparison of the code,
+// operation, message, violations and metadata of two errors,
+// ignoring their stacks, or "" if they match. Errors which are
+// not Errors are compared by their text. It is meant for test
+// failure messages:
+//
+//	if d := errors.Diff(want, err); d != "" {
+//		t.Errorf("unexpected error:\n%s", d)
+//	}
+func Diff(expected, actual error) string {
+	var buf strings.Builder
+	line := func(field string, want, got any) {
+		fmt.Fprintf(&buf, "%s: expected %#v, got %#v\n", field, want, got)
+	}
+	switch {
+	case IsNil(expected) && IsNil(actual):
+		return ""
+	case IsNil(expected):
+		line("error", nil, actual.Error())
+		return buf.String()
+	case IsNil(actual):
+		line("error", expected.Error(), nil)
+		return buf.String()
+	}
+	var want, got *Error
+	if !As(expected, &want) || !As(actual, &got) || want == nil || got == nil {
+		if expected.Error() != actual.Error() {
+			line("error", expected.Error(), actual.Error())
+		}
+		return buf.String()
+	}
+	if Code(want) != Code(got) {
+		line("code", Code(want), Code(got))
+	}
+	if want.Operation != got.Operation {
+		line("operation", want.Operation, got.Operation)
+	}
+	if Message(want) != Message(got) {
+		line("message", Message(want), Message(got))
+	}
+	diffViolations(&buf, Violations(want), Violations(got))
+	diffMetadata(&buf, want.Fields(), got.Fields())
+	return buf.String()
+}
+
+func diffViolations(buf *strings.Builder, want, got []FieldViolation) {
+	n := len(want)
+	if len(got) > n {
+		n = len(got)
+	}
+	for i := 0; i < n; i++ {
+		field := fmt.Sprintf("violations[%d]", i)
+		switch {
+		case i >= len(got):
+			fmt.Fprintf(buf, "%s: missing %+v\n", field, want[i])
+		case i >= len(want):
+			fmt.Fprintf(buf, "%s: unexpected %+v\n", field, got[i])
+		case want[i] != got[i]:
+			fmt.Fprintf(buf, "%s: expected %+v, got %+v\n", field, want[i], got[i])
+		}
+	}
+}
+
+func diffMetadata(buf *strings.Builder, want, got map[string]any) {
+	keys := make([]string, 0, len(want)+len(got))
+	for k := range want {
+		keys = append(keys, k)
+	}
+	for k := range got {
+		if _, ok := want[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		w, inWant := want[k]
+		g, inGot := got[k]
+		field := fmt.Sprintf("metadata[%q]", k)
+		switch {
+		case !inGot:
+			fmt.Fprintf(buf, "%s: missing %#v\n", field, w)
+		case !inWant:
+			fmt.Fprintf(buf, "%s: unexpected %#v\n", field, g)
+		case !reflect.DeepEqual(w, g):
+			fmt.Fprintf(buf, "%s: expected %#v, got %#v\n", field, w, g)
+		}
+	}
+}
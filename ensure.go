@@ -0,0 +1,136 @@
+package errors
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/oarkflow/errors/internal/hooks"
+)
+
+type ordered interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64 | ~string
+}
+
+// Ensure returns nil when cond is true. Otherwise, it returns an
+// Error with the given code, holding the failed condition, as
+// written at the call site, under the "expression" metadata key
+// when the source is available. The source line is read once per
+// call site.
+func Ensure(cond bool, code, message, op string) *Error {
+	if cond {
+		return nil
+	}
+	e := buildError(1, nil, message, code, op)
+	if _, file, line, ok := hooks.Caller(1); ok {
+		if expr := ensureExpression(file, line); expr != "" {
+			e.WithField("expression", expr)
+		}
+	}
+	notify(e)
+	return e
+}
+
+// CheckNotNil returns an INVALID Error if v is nil or a nil
+// pointer, map, slice, channel, function or interface.
+func CheckNotNil(v any, name, op string) *Error {
+	if !isNilValue(v) {
+		return nil
+	}
+	e := buildError(1, nil, name+" must not be nil", INVALID, op)
+	e.WithField("expression", name+" != nil")
+	notify(e)
+	return e
+}
+
+// CheckInRange returns an INVALID Error if v is not within the
+// inclusive range [min, max].
+func CheckInRange[T ordered](v, min, max T, name, op string) *Error {
+	if v >= min && v <= max {
+		return nil
+	}
+	e := buildError(1, nil, fmt.Sprintf("%s must be between %v and %v", name, min, max), INVALID, op)
+	e.WithField("expression", fmt.Sprintf("%v <= %s <= %v", min, name, max))
+	e.WithField("value", v)
+	notify(e)
+	return e
+}
+
+func isNilValue(v any) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
+
+type sourceLine struct {
+	file string
+	line int
+}
+
+// ensureExpressions caches the expressions of the Ensure call
+// sites by source line.
+var ensureExpressions sync.Map
+
+// ensureExpression returns the condition of the call to Ensure at
+// the given source line, reading the file on the first failure
+// only.
+func ensureExpression(file string, line int) string {
+	key := sourceLine{file, line}
+	if expr, ok := ensureExpressions.Load(key); ok {
+		return expr.(string)
+	}
+	expr := callExpression(file, line, "Ensure(")
+	ensureExpressions.Store(key, expr)
+	return expr
+}
+
+// callExpression returns the first argument of the call to fn
+// found on the given source line, if the file can be read.
+func callExpression(file string, line int, fn string) string {
+	f, err := os.Open(file)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		if n != line {
+			continue
+		}
+		src := scanner.Text()
+		i := strings.Index(src, fn)
+		if i < 0 {
+			return ""
+		}
+		src = src[i+len(fn):]
+		depth := 0
+		for j, r := range src {
+			switch r {
+			case '(', '[', '{':
+				depth++
+			case ')', ']', '}':
+				if depth == 0 {
+					return strings.TrimSpace(src[:j])
+				}
+				depth--
+			case ',':
+				if depth == 0 {
+					return strings.TrimSpace(src[:j])
+				}
+			}
+		}
+		return ""
+	}
+	return ""
+}
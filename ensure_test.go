@@ -0,0 +1,40 @@
+package errors_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/oarkflow/errors"
+	"github.com/oarkflow/errors/errtest"
+)
+
+func TestEnsureExpression(t *testing.T) {
+	n := 0
+	for i := 0; i < 2; i++ {
+		e := errors.Ensure(n > 0, errors.INVALID, "n must be positive", "check")
+		if got, _ := e.Meta("expression"); got != "n > 0" {
+			t.Errorf("expression = %v, want %q", got, "n > 0")
+		}
+	}
+}
+
+func TestEnsureExpressionCached(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "check.go")
+	write := func(src string) {
+		if err := os.WriteFile(file, []byte(src), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("\terrors.Ensure(a == b, errors.INVALID, \"\", \"\")\n")
+	errtest.SetCaller(t, file, 1)
+
+	first := errors.Ensure(false, errors.INVALID, "", "")
+	write("\terrors.Ensure(changed, errors.INVALID, \"\", \"\")\n")
+	second := errors.Ensure(false, errors.INVALID, "", "")
+	for _, e := range []*errors.Error{first, second} {
+		if got, _ := e.Meta("expression"); got != "a == b" {
+			t.Errorf("expression = %v, want %q", got, "a == b")
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package errors
+
+import (
+	"fmt"
+)
+
+const (
+	// MetaEntity is the metadata key of the entity kind of the errors
+	// built by NewNotFoundEntity and its variants.
+	MetaEntity = "entity"
+	// MetaEntityID is the metadata key of the entity identifier.
+	MetaEntityID = "entity_id"
+)
+
+// EntityMessages holds the message formats of the entity errors,
+// keyed by code. They receive the entity kind and, when given, the
+// identifier joined by a space, e.g. "user 42".
+var EntityMessages = map[string]string{
+	NOTFOUND: "%s not found",
+	CONFLICT: "%s already exists",
+	INVALID:  "%s is invalid",
+}
+
+// NewNotFoundEntity returns an Error with a NOTFOUND error code for
+// the entity of given kind and identifier, e.g. "user 42 not found".
+// Both are stored in the metadata.
+func NewNotFoundEntity(entity, id, op string, disableErrorHandler ...bool) *Error {
+	return newEntityError(entity, id, NOTFOUND, op, disableErrorHandler...)
+}
+
+// NewConflictEntity returns an Error with a CONFLICT error code for
+// the entity of given kind and identifier, e.g. "user 42 already
+// exists". Both are stored in the metadata.
+func NewConflictEntity(entity, id, op string, disableErrorHandler ...bool) *Error {
+	return newEntityError(entity, id, CONFLICT, op, disableErrorHandler...)
+}
+
+// NewInvalidEntity returns an Error with a INVALID error code for
+// the entity of given kind and identifier, e.g. "user 42 is
+// invalid". Both are stored in the metadata.
+func NewInvalidEntity(entity, id, op string, disableErrorHandler ...bool) *Error {
+	return newEntityError(entity, id, INVALID, op, disableErrorHandler...)
+}
+
+func newEntityError(entity, id, code, op string, disableErrorHandler ...bool) *Error {
+	e := buildError(2, nil, entityMessage(code, entity, id), code, op)
+	e.Metadata = map[string]any{MetaEntity: entity}
+	if id != "" {
+		e.Metadata[MetaEntityID] = id
+	}
+	notify(e, disableErrorHandler...)
+	return e
+}
+
+func entityMessage(code, entity, id string) string {
+	subject := entity
+	if id != "" {
+		subject += " " + id
+	}
+	format, ok := EntityMessages[code]
+	if !ok {
+		return subject
+	}
+	return fmt.Sprintf(format, subject)
+}
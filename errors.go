@@ -10,6 +10,10 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/oarkflow/errors/internal/hooks"
 )
 
 type ErrorCallbackHandler func(err *Error)
@@ -108,6 +112,16 @@ func NewExpired(err error, message, op string, disableErrorHandler ...bool) *Err
 	return newError(err, message, EXPIRED, op, disableErrorHandler...)
 }
 
+// NewSkip returns an Error with the given code, recording the
+// location of the caller skip frames above the caller of NewSkip.
+// It is meant for helpers and generated constructors which
+// should not appear as the origin of the error.
+func NewSkip(skip int, err error, message, code, op string, disableErrorHandler ...bool) *Error {
+	e := buildError(skip+1, err, message, code, op)
+	notify(e, disableErrorHandler...)
+	return e
+}
+
 // NewE returns an Error with the DefaultCode.
 func NewE(err error, message, op string, disableErrorHandler ...bool) *Error {
 	return newError(err, message, DefaultCode, op, disableErrorHandler...)
@@ -116,88 +130,76 @@ func NewE(err error, message, op string, disableErrorHandler ...bool) *Error {
 // ErrorF returns an Error with the DefaultCode and
 // formatted message arguments.
 func ErrorF(err error, op, format string, disableErrorHandler bool, args ...any) *Error {
-	return NewE(err, fmt.Sprintf(format, args...), op, disableErrorHandler)
+	return newError(err, fmt.Sprintf(format, args...), DefaultCode, op, disableErrorHandler)
 }
 
-// Wrap returns an error annotating err with a stack trace
+// Wrap returns an Error annotating err with a stack trace
 // at the point Wrap is called, and the supplied message.
-// If err is nil, Wrap returns nil.
-func Wrap(err error, message, op string) *Error {
+// If err is nil, Wrap returns a true nil error.
+func Wrap(err error, message, op string) error {
 	if err == nil {
 		return nil
 	}
-	return NewE(err, message, op, true)
+	e := buildError(1, err, message, DefaultCode, op)
+	notify(e)
+	return e
 }
 
 // newError is an alias for New by creating the pcs
 // file line and constructing the error message.
 func newError(err error, message, code, op string, disableErrorHandler ...bool) *Error {
-	_, file, line, _ := runtime.Caller(2)
-	pcs := make([]uintptr, 2)
-	_ = runtime.Callers(2, pcs)
-	var stackTrace StackTrace
-	for i, pc := range pcs {
-		p := runtime.FuncForPC(pc)
-		f, l := p.FileLine(pc)
-		stackTrace = append(stackTrace, Trace{
-			Index:    i,
-			Function: p.Name(),
-			File:     f,
-			Line:     l,
-		})
-	}
-	e := &Error{
-		Code:       code,
-		Message:    message,
-		Operation:  op,
-		Err:        err,
-		Additional: stackTrace,
-		fileLine:   file + ":" + strconv.Itoa(line),
-		pcs:        pcs,
-	}
-	if code == INTERNAL {
-		e.Internal = true
-	}
-	if DefaultErrorCallbackHandler != nil && ((len(disableErrorHandler) > 0 && disableErrorHandler[0]) || len(disableErrorHandler) == 0) {
-		DefaultErrorCallbackHandler(e)
-	}
+	e := buildError(2, err, message, code, op)
+	notify(e, disableErrorHandler...)
 	return e
 }
 
 // newErrorWithContext is an alias for New by creating the pcs
 // file line and constructing the error message.
 func newErrorWithContext(ctx context.Context, err error, message, code, op string, disableErrorHandler ...bool) *Error {
-	_, file, line, _ := runtime.Caller(2)
-	pcs := make([]uintptr, 2)
-	_ = runtime.Callers(2, pcs)
-	var stackTrace StackTrace
-	for i, pc := range pcs {
-		p := runtime.FuncForPC(pc)
-		f, l := p.FileLine(pc)
-		stackTrace = append(stackTrace, Trace{
-			Index:    i,
-			Function: p.Name(),
-			File:     f,
-			Line:     l,
-		})
+	e := buildError(2, err, message, code, contextOp(ctx, op))
+	e.Context = ctx
+	notify(e, disableErrorHandler...)
+	return e
+}
+
+// buildError constructs the Error, capturing the file line of
+// the caller skip frames above the caller of buildError, and the
+// stack unless it is reused from err, see ReuseCauseStack.
+func buildError(skip int, err error, message, code, op string) *Error {
+	pc, file, line, _ := hooks.Caller(skip + 1)
+	pcs := causeStack(err)
+	if pcs == nil {
+		pcs = callers(skip)
 	}
 	e := &Error{
-		Context:    ctx,
-		Code:       code,
-		Message:    message,
-		Operation:  op,
-		Err:        err,
-		Additional: stackTrace,
-		fileLine:   file + ":" + strconv.Itoa(line),
-		pcs:        pcs,
+		Code:      code,
+		Message:   message,
+		Operation: op,
+		Err:       err,
+		fileLine:  file + ":" + strconv.Itoa(line),
+		caller:    newCaller(pc, file, line),
+		pcs:       pcs,
 	}
+	e.Additional = e.frames(additionalDepth)
 	if code == INTERNAL {
 		e.Internal = true
 	}
+	if err != nil && cyclic(err) {
+		e.Err = ErrCyclicWrap
+		e.Metadata = map[string]any{MetaCyclic: true}
+	} else if err != nil {
+		e.captureCause(err)
+	}
+	recordOp(op)
+	return e
+}
+
+// notify calls the DefaultErrorCallbackHandler with the error
+// unless the handler has been disabled.
+func notify(e *Error, disableErrorHandler ...bool) {
 	if DefaultErrorCallbackHandler != nil && ((len(disableErrorHandler) > 0 && disableErrorHandler[0]) || len(disableErrorHandler) == 0) {
 		DefaultErrorCallbackHandler(e)
 	}
-	return e
 }
 
 // Application error codes.
@@ -216,6 +218,16 @@ const (
 	MAXIMUMATTEMPTS = "maximum_attempts"
 	// EXPIRED - Subscription expired.
 	EXPIRED = "expired"
+	// UNAVAILABLE - A dependency or the service is temporarily
+	// unavailable.
+	UNAVAILABLE = "unavailable"
+	// FORBIDDEN - Permission denied.
+	FORBIDDEN = "forbidden"
+	// TIMEOUT - An operation did not complete in time.
+	TIMEOUT = "timeout"
+	// RESOURCEEXHAUSTED - A resource such as disk space, memory
+	// or file descriptors ran out.
+	RESOURCEEXHAUSTED = "resource_exhausted"
 )
 
 var (
@@ -228,22 +240,62 @@ var (
 )
 
 // Error defines a standard application error.
+//
+// The exported fields are meant to be set when the error is
+// built. Once an error is shared between goroutines, annotate it
+// with WithField, which is safe for concurrent use alongside
+// Error, MarshalJSON and Meta, or relabel it with the
+// copy-on-write WithOp, WithCode and WithMessage.
 type Error struct {
-	Code          string     `json:"code"`
-	Message       string     `json:"message"`
-	Operation     string     `json:"operation"`
-	Err           error      `json:"error"`
-	Additional    StackTrace `json:"additional"`
-	Internal      bool       `json:"internal"`
-	NotifyHandler bool       `json:"notify_handler"`
+	Code          string           `json:"code"`
+	Message       string           `json:"message"`
+	Operation     string           `json:"operation"`
+	Err           error            `json:"error"`
+	Additional    StackTrace       `json:"additional"`
+	Internal      bool             `json:"internal"`
+	Severity      Severity         `json:"severity,omitempty"`
+	Metadata      map[string]any   `json:"metadata,omitempty"`
+	Violations    []FieldViolation `json:"violations,omitempty"`
+	Retryable     bool             `json:"retryable,omitempty"`
+	NotifyHandler bool             `json:"notify_handler"`
+	PanicValue    any              `json:"panic_value,omitempty"`
 	Context       context.Context
 	fileLine      string
+	caller        Caller
 	pcs           []uintptr
+	version       int
+	id            string
+	occurrences   int64
+	firstSeen     time.Time
+	lastSeen      time.Time
+	notes         []Note
+	extra         map[string]json.RawMessage
+	mu            sync.RWMutex
 }
 
 // Error returns the string representation of the error
-// message by implementing the error interface.
+// message by implementing the error interface. It is rendered in
+// the DefaultLocale when one is registered, see ErrorIn.
 func (e *Error) Error() string {
+	if DefaultLocale != "" {
+		if l, ok := lookupLocale(DefaultLocale); ok {
+			return e.localize(l, 0)
+		}
+	}
+	return e.errorString(0, true)
+}
+
+// errorString formats the error, with depth the number of Errors
+// wrapping it, so that a chain made cyclic by assigning Err
+// directly cannot recurse forever. The file lines are left out
+// unless withLocation is set.
+func (e *Error) errorString(depth int, withLocation bool) string {
+	if e == nil {
+		return ""
+	}
+	if depth >= maxTreeDepth {
+		return "..."
+	}
 	var buf bytes.Buffer
 
 	// Print the error code if there is one.
@@ -252,7 +304,7 @@ func (e *Error) Error() string {
 	}
 
 	// Print the file-line, if any.
-	if e.fileLine != "" {
+	if e.fileLine != "" && withLocation {
 		buf.WriteString(e.fileLine + " - ")
 	}
 
@@ -262,7 +314,9 @@ func (e *Error) Error() string {
 	}
 
 	// Print the original error message, if any.
-	if e.Err != nil {
+	if er, ok := e.Err.(*Error); ok && er != nil {
+		buf.WriteString(er.errorString(depth+1, withLocation) + ", ")
+	} else if e.Err != nil {
 		buf.WriteString(e.Err.Error() + ", ")
 	}
 
@@ -275,65 +329,154 @@ func (e *Error) Error() string {
 }
 
 func (e *Error) ErrorWithStackTrace() string {
+	if e == nil {
+		return ""
+	}
 	var buf bytes.Buffer
-	buf.WriteString("Type: ")
-	buf.WriteString(e.Code)
-	buf.WriteString(", Message: ")
-	buf.WriteString(e.Message)
-	buf.WriteString(", Operation: ")
-	buf.WriteString(e.Operation)
-	buf.WriteString("\n")
-	buf.WriteString(e.Additional.String())
-	switch er := e.Err.(type) {
-	case *Error:
-		buf.WriteString("\n")
-		buf.WriteString(er.ErrorWithStackTrace())
-		buf.WriteString("\n")
-	case error:
+	chain := e.Chain()
+	for i, c := range chain {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		buf.WriteString("Type: ")
+		buf.WriteString(c.Code)
+		buf.WriteString(", Message: ")
+		buf.WriteString(c.Message)
+		buf.WriteString(", Operation: ")
+		buf.WriteString(c.Operation)
 		buf.WriteString("\n")
-		buf.WriteString(er.Error())
+		if c.HasStack() {
+			buf.WriteString(c.Additional.String())
+		}
+		for _, n := range c.Notes() {
+			buf.WriteString("Note: " + n.Text)
+			if n.FileLine != "" {
+				buf.WriteString(" (" + n.FileLine + ")")
+			}
+			buf.WriteString("\n")
+		}
 	}
+	if root := chain[len(chain)-1].Err; root != nil {
+		if _, ok := root.(*Error); !ok {
+			buf.WriteString("\n")
+			buf.WriteString(root.Error())
+		}
+	}
+	buf.WriteString(strings.Repeat("\n", len(chain)-1))
 	return buf.String()
 }
 
+// WithField stores the value under key in the metadata of the
+// error and returns the error for chaining.
+func (e *Error) WithField(key string, value any) *Error {
+	if e == nil {
+		return nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.Metadata == nil {
+		e.Metadata = make(map[string]any)
+	}
+	e.Metadata[key] = value
+	return e
+}
+
+// Meta returns the metadata value stored under key.
+func (e *Error) Meta(key string) (any, bool) {
+	if e == nil {
+		return nil, false
+	}
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	v, ok := e.Metadata[key]
+	return v, ok
+}
+
+// Fields returns a copy of the metadata of the error.
+func (e *Error) Fields() map[string]any {
+	if e == nil {
+		return nil
+	}
+	return e.metadata()
+}
+
 // FileLine returns the file and line in which the error
 // occurred.
 func (e *Error) FileLine() string {
+	if e == nil {
+		return ""
+	}
 	return e.fileLine
 }
 
 // Unwrap unwraps the original error message.
 func (e *Error) Unwrap() error {
+	if e == nil {
+		return nil
+	}
 	return e.Err
 }
 
 // HTTPStatusCode is a convenience method used to get the appropriate
-// HTTP response status code for the respective error type.
+// HTTP response status code for the respective error type. It
+// returns 0 for a nil Error.
 func (e *Error) HTTPStatusCode() int {
-	status := http.StatusInternalServerError
-	switch e.Code {
+	if e == nil {
+		return 0
+	}
+	for code := e.Code; code != ""; code = ParentCode(code) {
+		if info, ok := lookupCode(code); ok && info.HTTPStatus != 0 {
+			return info.HTTPStatus
+		}
+		if status, ok := httpStatus(code); ok {
+			return status
+		}
+	}
+	return http.StatusInternalServerError
+}
+
+// httpStatus returns the HTTP status of the builtin codes.
+func httpStatus(code string) (int, bool) {
+	switch code {
 	case CONFLICT:
-		return http.StatusConflict
+		return http.StatusConflict, true
 	case INVALID:
-		return http.StatusBadRequest
+		return http.StatusBadRequest, true
 	case NOTFOUND:
-		return http.StatusNotFound
+		return http.StatusNotFound, true
 	case EXPIRED:
-		return http.StatusPaymentRequired
+		return http.StatusPaymentRequired, true
 	case MAXIMUMATTEMPTS:
-		return http.StatusTooManyRequests
+		return http.StatusTooManyRequests, true
+	case UNAVAILABLE:
+		return http.StatusServiceUnavailable, true
+	case FORBIDDEN:
+		return http.StatusForbidden, true
+	case TIMEOUT:
+		return http.StatusGatewayTimeout, true
+	case RESOURCEEXHAUSTED:
+		return http.StatusInsufficientStorage, true
 	}
-	return status
+	return 0, false
 }
 
-// RuntimeFrames returns function/file/line information.
+// RuntimeFrames returns function/file/line information of the
+// local program counters. It yields no frames for decoded errors,
+// see StackFrames.
 func (e *Error) RuntimeFrames() *runtime.Frames {
+	if e == nil {
+		return runtime.CallersFrames(nil)
+	}
 	return runtime.CallersFrames(e.pcs)
 }
 
 // ProgramCounters returns the slice of PC values associated
-// with the error.
+// with the error. It is nil for decoded errors, as program
+// counters are only meaningful in the process which created them.
 func (e *Error) ProgramCounters() []uintptr {
+	if e == nil {
+		return nil
+	}
 	return e.pcs
 }
 
@@ -341,32 +484,30 @@ func (e *Error) ProgramCounters() []uintptr {
 // stacktrace, where each trace is separated by a newline
 // and tab '\t'.
 func (e *Error) StackTrace() string {
-	trace := make([]string, 0, 100)
-	rFrames := e.RuntimeFrames()
-	frame, ok := rFrames.Next()
-	line := strconv.Itoa(frame.Line)
-	trace = append(trace, frame.Function+"(): "+e.Message)
-
-	for ok {
-		trace = append(trace, "\t"+frame.File+":"+line)
-		frame, ok = rFrames.Next()
+	trace := e.StackTraceSlice()
+	for i := 1; i < len(trace); i++ {
+		trace[i] = "\t" + trace[i]
 	}
-
 	return strings.Join(trace, "\n")
 }
 
 // StackTraceSlice returns a string slice of the errors
-// stacktrace.
+// stacktrace. The first entry holds the function in which the
+// error was created and its message, followed by the location
+// and function of every frame of StackFrames. It returns nil when
+// the error has no frames.
 func (e *Error) StackTraceSlice() []string {
-	trace := make([]string, 0, 100)
-	rFrames := e.RuntimeFrames()
-	frame, ok := rFrames.Next()
-	line := strconv.Itoa(frame.Line)
-	trace = append(trace, frame.Function+"(): "+e.Message)
-
-	for ok {
-		trace = append(trace, frame.File+":"+line)
-		frame, ok = rFrames.Next()
+	if e == nil {
+		return nil
+	}
+	frames := e.StackFrames()
+	if len(frames) == 0 {
+		return nil
+	}
+	trace := make([]string, 0, len(frames)+1)
+	trace = append(trace, frames[0].Function+"(): "+e.Message)
+	for _, frame := range frames {
+		trace = append(trace, frame.File+":"+strconv.Itoa(frame.Line)+" "+frame.Function)
 	}
 	return trace
 }
@@ -374,30 +515,33 @@ func (e *Error) StackTraceSlice() []string {
 // wrappingError is the wrapping error features the error
 // and file line in strings suitable for json.Marshal.
 type wrappingError struct {
-	Code       string     `json:"code"`
-	Message    string     `json:"message"`
-	Operation  string     `json:"operation"`
-	Err        string     `json:"error"`
-	FileLine   string     `json:"file_line"`
-	Additional StackTrace `json:"additional"`
-	Internal   bool       `json:"internal"`
+	Version     int              `json:"v,omitempty"`
+	ID          string           `json:"id,omitempty"`
+	Code        string           `json:"code"`
+	TopCode     string           `json:"top_code,omitempty"`
+	Message     string           `json:"message"`
+	Operation   string           `json:"operation"`
+	Err         string           `json:"error"`
+	FileLine    string           `json:"file_line"`
+	Caller      Caller           `json:"caller"`
+	Additional  StackTrace       `json:"-"`
+	Internal    bool             `json:"internal"`
+	Severity    Severity         `json:"severity,omitempty"`
+	Metadata    map[string]any   `json:"metadata,omitempty"`
+	Violations  []FieldViolation `json:"violations,omitempty"`
+	Retryable   bool             `json:"retryable,omitempty"`
+	Occurrences int64            `json:"occurrences,omitempty"`
+	FirstSeen   *time.Time       `json:"first_seen,omitempty"`
+	LastSeen    *time.Time       `json:"last_seen,omitempty"`
+	PanicValue  any              `json:"panic_value,omitempty"`
+	Notes       []Note           `json:"notes,omitempty"`
 }
 
 // MarshalJSON implements encoding/Marshaller to wrap the
-// error as a string if there is one.
+// error as a string if there is one. The output is shaped by
+// DefaultMarshalOptions.
 func (e *Error) MarshalJSON() ([]byte, error) {
-	err := wrappingError{
-		Code:       e.Code,
-		Message:    e.Message,
-		Operation:  e.Operation,
-		Additional: e.Additional,
-		Internal:   e.Internal,
-	}
-	if e.Err != nil {
-		err.Err = e.Err.Error()
-		err.FileLine = e.fileLine
-	}
-	return json.Marshal(err)
+	return e.MarshalJSONWithOptions(DefaultMarshalOptions)
 }
 
 func (e *Error) JSONAsString() (string, error) {
@@ -408,30 +552,75 @@ func (e *Error) JSONAsString() (string, error) {
 // UnmarshalJSON implements encoding/Marshaller to unmarshal
 // the wrapping error to type Error.
 func (e *Error) UnmarshalJSON(data []byte) error {
-	var err wrappingError
-	mErr := json.Unmarshal(data, &err)
+	return e.UnmarshalJSONWithOptions(data, DefaultMarshalOptions)
+}
+
+// UnmarshalJSONWithOptions decodes the JSON encoding produced by
+// MarshalJSONWithOptions with the same field names of opts.
+func (e *Error) UnmarshalJSONWithOptions(data []byte, opts MarshalOptions) error {
+	if e == nil {
+		return fmt.Errorf("unmarshal into nil *errors.Error")
+	}
+	data, extra, mErr := opts.splitFields(data)
 	if mErr != nil {
 		return mErr
 	}
+	var err wrappingError
+	if mErr = json.Unmarshal(data, &err); mErr != nil {
+		return mErr
+	}
+	if err.Additional, mErr = unmarshalStack(data, StackFieldName); mErr != nil {
+		return mErr
+	}
 	e.Code = err.Code
 	e.Message = err.Message
 	e.Operation = err.Operation
 	e.Additional = err.Additional
 	e.Internal = err.Internal
+	e.Severity = err.Severity
+	e.mu.Lock()
+	e.Metadata = err.Metadata
+	e.extra = extra
+	e.id = err.ID
+	e.occurrences = err.Occurrences
+	e.firstSeen, e.lastSeen = time.Time{}, time.Time{}
+	if err.FirstSeen != nil {
+		e.firstSeen = *err.FirstSeen
+	}
+	if err.LastSeen != nil {
+		e.lastSeen = *err.LastSeen
+	}
+	e.notes = err.Notes
+	e.mu.Unlock()
+	e.version = err.Version
+	e.Violations = err.Violations
+	e.Retryable = err.Retryable
 	e.fileLine = err.FileLine
+	e.caller = err.Caller
+	e.PanicValue = err.PanicValue
 	if err.Err != "" {
 		e.Err = errors.New(err.Err)
 	}
 	return nil
 }
 
-// Scan implements the sql.Scanner interface.
+// Scan implements the sql.Scanner interface, decoding the JSON
+// stored in a []byte or string column.
 func (e *Error) Scan(value any) error {
 	if value == nil {
 		return nil
 	}
-	buf, ok := value.([]byte)
-	if !ok || buf == nil {
+	if e == nil {
+		return fmt.Errorf("scan into nil *errors.Error")
+	}
+	var buf []byte
+	switch v := value.(type) {
+	case []byte:
+		buf = v
+	case string:
+		buf = []byte(v)
+	}
+	if buf == nil {
 		return fmt.Errorf("scan not supported for *errors.Error")
 	}
 	return json.Unmarshal(buf, e)
@@ -439,5 +628,8 @@ func (e *Error) Scan(value any) error {
 
 // Value implements the driver.Valuer interface.
 func (e *Error) Value() ([]byte, error) {
+	if e == nil {
+		return nil, nil
+	}
 	return json.Marshal(e)
 }
@@ -0,0 +1,128 @@
+// Package errorspb defines the protobuf representation of
+// application errors, generated from errors.proto, and converts
+// between it and *errors.Error.
+package errorspb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative errors.proto
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/oarkflow/errors"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// maxDepth bounds the nesting of causes converted.
+const maxDepth = 64
+
+// ToProto converts err to its protobuf representation. Wrapped
+// Errors are kept as nested causes; other causes are reduced to
+// their text. If err is nil, ToProto returns nil.
+func ToProto(err error) *Error {
+	if errors.IsNil(err) {
+		return nil
+	}
+	return toProto(errors.From(err), 0)
+}
+
+func toProto(e *errors.Error, depth int) *Error {
+	pb := &Error{
+		Code:      e.Code,
+		Message:   e.Message,
+		Operation: e.Operation,
+		Internal:  e.Internal,
+		Severity:  int32(e.Severity),
+		Retryable: e.Retryable,
+	}
+	if fields := e.Fields(); len(fields) > 0 {
+		pb.Metadata = make(map[string]*structpb.Value, len(fields))
+		for k, v := range fields {
+			pb.Metadata[k] = toValue(v)
+		}
+	}
+	for _, t := range e.StackFrames() {
+		pb.Stack = append(pb.Stack, &Frame{Function: t.Function, File: t.File, Line: int32(t.Line)})
+	}
+	for _, v := range e.Violations {
+		pb.Violations = append(pb.Violations, &FieldViolation{
+			Field:   v.Field,
+			Rule:    v.Rule,
+			Param:   v.Param,
+			Message: v.Message,
+		})
+	}
+	if e.Err != nil {
+		var cause *errors.Error
+		if errors.As(e.Err, &cause) && cause != nil && depth < maxDepth {
+			pb.Cause = toProto(cause, depth+1)
+		} else {
+			pb.CauseMessage = e.Err.Error()
+		}
+	}
+	return pb
+}
+
+// toValue converts a metadata value, going through its JSON
+// encoding for types structpb does not support.
+func toValue(v any) *structpb.Value {
+	if pv, err := structpb.NewValue(v); err == nil {
+		return pv
+	}
+	if b, err := json.Marshal(v); err == nil {
+		var decoded any
+		if json.Unmarshal(b, &decoded) == nil {
+			if pv, err := structpb.NewValue(decoded); err == nil {
+				return pv
+			}
+		}
+	}
+	return structpb.NewStringValue(fmt.Sprint(v))
+}
+
+// FromProto converts the protobuf representation back to an
+// Error, with its stack frames in Additional. If pb is nil,
+// FromProto returns nil.
+func FromProto(pb *Error) *errors.Error {
+	return fromProto(pb, 0)
+}
+
+func fromProto(pb *Error, depth int) *errors.Error {
+	if pb == nil {
+		return nil
+	}
+	e := &errors.Error{
+		Code:      pb.GetCode(),
+		Message:   pb.GetMessage(),
+		Operation: pb.GetOperation(),
+		Internal:  pb.GetInternal(),
+		Severity:  errors.Severity(pb.GetSeverity()),
+		Retryable: pb.GetRetryable(),
+	}
+	for k, v := range pb.GetMetadata() {
+		e.WithField(k, v.AsInterface())
+	}
+	for i, f := range pb.GetStack() {
+		e.Additional = append(e.Additional, errors.Trace{
+			Index:    i,
+			Function: f.GetFunction(),
+			File:     f.GetFile(),
+			Line:     int(f.GetLine()),
+		})
+	}
+	for _, v := range pb.GetViolations() {
+		e.Violations = append(e.Violations, errors.FieldViolation{
+			Field:   v.GetField(),
+			Rule:    v.GetRule(),
+			Param:   v.GetParam(),
+			Message: v.GetMessage(),
+		})
+	}
+	switch {
+	case pb.GetCause() != nil && depth < maxDepth:
+		e.Err = fromProto(pb.GetCause(), depth+1)
+	case pb.GetCauseMessage() != "":
+		e.Err = errors.New(pb.GetCauseMessage())
+	}
+	return e
+}
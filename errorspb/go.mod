@@ -0,0 +1,10 @@
+module github.com/oarkflow/errors/errorspb
+
+go 1.20
+
+require (
+	github.com/oarkflow/errors v0.0.0
+	google.golang.org/protobuf v1.31.0
+)
+
+replace github.com/oarkflow/errors => ../
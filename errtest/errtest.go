@@ -0,0 +1,174 @@
+// Package errtest provides helpers for testing the errors of an
+// application, such as golden files of their JSON payloads and
+// assertions on the HTTP responses written by the Responder, and
+// replaces the clock and caller of the errors package to make
+// timestamps and locations deterministic.
+package errtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"mime"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/oarkflow/errors"
+	"github.com/oarkflow/errors/internal/hooks"
+)
+
+// updateFlag is the -errtest.update flag rewriting the golden
+// files. It is namespaced, as errtest is initialised before the
+// test package, which may define its own -update flag.
+var updateFlag = flag.Bool("errtest.update", false, "update the golden files of errtest.Golden")
+
+// update reports whether the golden files are rewritten, with
+// -errtest.update or with an -update flag of the test package,
+// looked up once the flags are parsed.
+func update() bool {
+	if *updateFlag {
+		return true
+	}
+	if f := flag.Lookup("update"); f != nil {
+		return f.Value.String() == "true"
+	}
+	return false
+}
+
+// Options are the marshal options used by Marshal.
+var Options = errors.MarshalOptions{Deterministic: true}
+
+// Marshal returns the deterministic, indented JSON encoding of
+// err, which only depends on its content.
+func Marshal(err error) ([]byte, error) {
+	var raw []byte
+	if e := asError(err); e != nil {
+		b, mErr := e.MarshalJSONWithOptions(Options)
+		if mErr != nil {
+			return nil, mErr
+		}
+		raw = b
+	} else if err != nil {
+		b, mErr := json.Marshal(map[string]string{"error": err.Error()})
+		if mErr != nil {
+			return nil, mErr
+		}
+		raw = b
+	} else {
+		raw = []byte("null")
+	}
+	var buf bytes.Buffer
+	if iErr := json.Indent(&buf, raw, "", "  "); iErr != nil {
+		return nil, iErr
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+func asError(err error) *errors.Error {
+	var e *errors.Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return nil
+}
+
+// Golden compares the payload of err, as returned by Marshal,
+// with the golden file at path, relative to the package directory
+// unless absolute. Running the tests with -errtest.update, or with
+// the -update flag of the test package if it has one, writes the
+// payload to the file instead.
+func Golden(t testing.TB, path string, err error) {
+	t.Helper()
+	got, mErr := Marshal(err)
+	if mErr != nil {
+		t.Fatalf("errtest: marshal error: %v", mErr)
+	}
+	if update() {
+		if dErr := os.MkdirAll(filepath.Dir(path), 0o755); dErr != nil {
+			t.Fatalf("errtest: %v", dErr)
+		}
+		if wErr := os.WriteFile(path, got, 0o644); wErr != nil {
+			t.Fatalf("errtest: %v", wErr)
+		}
+		return
+	}
+	want, rErr := os.ReadFile(path)
+	if rErr != nil {
+		t.Fatalf("errtest: %v (run with -errtest.update to create it)", rErr)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("errtest: payload differs from %s (run with -errtest.update to accept it)\n--- want\n%s--- got\n%s", path, want, got)
+	}
+}
+
+// SetClock makes the errors package read the time from now until
+// the end of the test, e.g. to assert on occurrence times and log
+// timestamps. It must not be used by parallel tests.
+func SetClock(t testing.TB, now func() time.Time) {
+	t.Helper()
+	prev := hooks.Now
+	hooks.Now = now
+	t.Cleanup(func() { hooks.Now = prev })
+}
+
+// FixedClock returns a clock always returning at, for SetClock.
+func FixedClock(at time.Time) func() time.Time {
+	return func() time.Time { return at }
+}
+
+// SetCaller makes the errors created until the end of the test
+// record file and line as their location instead of the actual
+// caller. The stack frames are still captured. It must not be used
+// by parallel tests.
+func SetCaller(t testing.TB, file string, line int) {
+	t.Helper()
+	prev := hooks.Caller
+	hooks.Caller = func(int) (uintptr, string, int, bool) { return 0, file, line, true }
+	t.Cleanup(func() { hooks.Caller = prev })
+}
+
+// AssertHTTPError checks that rec holds an error response written
+// by the Responder, in JSON or problem+json, with the status
+// wantStatus, the code wantCode and a message. It returns the error
+// decoded from the body for further assertions. The JSON body is
+// decoded with the first of opts, which must be the MarshalOptions
+// of the Responder, or errors.DefaultMarshalOptions.
+func AssertHTTPError(t testing.TB, rec *httptest.ResponseRecorder, wantCode string, wantStatus int, opts ...errors.MarshalOptions) *errors.Error {
+	t.Helper()
+	if rec.Code != wantStatus {
+		t.Errorf("errtest: status = %d, want %d", rec.Code, wantStatus)
+	}
+	mediaType, _, _ := mime.ParseMediaType(rec.Header().Get("Content-Type"))
+	body := rec.Body.Bytes()
+	var e *errors.Error
+	switch mediaType {
+	case errors.MediaJSON:
+		o := errors.DefaultMarshalOptions
+		if len(opts) > 0 {
+			o = opts[0]
+		}
+		e = &errors.Error{}
+		if err := e.UnmarshalJSONWithOptions(body, o); err != nil {
+			t.Fatalf("errtest: decode error body: %v\n%s", err, body)
+		}
+	case errors.MediaProblem:
+		var p errors.Problem
+		if err := json.Unmarshal(body, &p); err != nil {
+			t.Fatalf("errtest: decode problem body: %v\n%s", err, body)
+		}
+		e = &errors.Error{Code: p.Code, Message: p.Detail, Operation: p.Operation, Violations: p.Violations}
+	default:
+		t.Fatalf("errtest: Content-Type = %q, want %s or %s", mediaType, errors.MediaJSON, errors.MediaProblem)
+	}
+	if e.Code != wantCode {
+		t.Errorf("errtest: code = %q, want %q", e.Code, wantCode)
+	}
+	if e.Message == "" {
+		t.Errorf("errtest: error response without message\n%s", body)
+	}
+	return e
+}
@@ -0,0 +1,32 @@
+package errtest_test
+
+import (
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/oarkflow/errors"
+	"github.com/oarkflow/errors/errtest"
+)
+
+// update is defined like in the test packages using errtest, which
+// must not conflict with the flag of errtest.
+var update = flag.Bool("update", false, "update the golden files")
+
+func TestGolden(t *testing.T) {
+	errtest.Golden(t, "testdata/not_found.json", errors.NewNotFound(nil, "no user", "users.Get", false))
+}
+
+func TestAssertHTTPErrorOptions(t *testing.T) {
+	rs := errors.NewResponder()
+	rs.MarshalOptions = errors.MarshalOptions{FieldNames: map[string]string{"code": "error_code", "message": "detail"}}
+	rec := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	rs.WriteError(rec, r, errors.NewNotFound(nil, "no user", "users.Get", false))
+
+	e := errtest.AssertHTTPError(t, rec, errors.NOTFOUND, http.StatusNotFound, rs.MarshalOptions)
+	if e.Message != "no user" {
+		t.Errorf("message = %q, want %q", e.Message, "no user")
+	}
+}
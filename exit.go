@@ -0,0 +1,54 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+var (
+	// ExitWriter is where Exit prints the error.
+	ExitWriter io.Writer = os.Stderr
+
+	osExit      = os.Exit
+	exitCodesMu sync.RWMutex
+	exitCodes   = map[string]int{
+		INTERNAL: 1,
+		INVALID:  2,
+		NOTFOUND: 3,
+	}
+)
+
+// RegisterExitCode sets the process exit code returned by
+// ExitCode for errors with the given code.
+func RegisterExitCode(code string, exitCode int) {
+	exitCodesMu.Lock()
+	defer exitCodesMu.Unlock()
+	exitCodes[code] = exitCode
+}
+
+// ExitCode returns the process exit code for the error: 0 when
+// err is nil, the registered exit code of its code, otherwise 1.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	exitCodesMu.RLock()
+	defer exitCodesMu.RUnlock()
+	if c, ok := exitCodes[Code(err)]; ok {
+		return c
+	}
+	return 1
+}
+
+// Exit prints the error using Pretty and terminates the
+// program with its ExitCode. If err is nil, Exit returns
+// without doing anything.
+func Exit(err error) {
+	if err == nil {
+		return
+	}
+	_, _ = fmt.Fprintln(ExitWriter, Pretty(err))
+	osExit(ExitCode(err))
+}
@@ -0,0 +1,64 @@
+package errors
+
+import (
+	"sync"
+)
+
+var (
+	fallbackMu       sync.RWMutex
+	fallbackMessages = map[string]string{
+		CONFLICT:          "The resource is in conflict with its current state.",
+		INVALID:           "The request is invalid.",
+		NOTFOUND:          "Resource not found.",
+		MAXIMUMATTEMPTS:   "Too many attempts, please retry later.",
+		EXPIRED:           "The resource has expired.",
+		UNAVAILABLE:       "The service is unavailable, please retry later.",
+		FORBIDDEN:         "Access is forbidden.",
+		TIMEOUT:           "The operation timed out.",
+		RESOURCEEXHAUSTED: "Resources are exhausted.",
+	}
+)
+
+// SetFallbackMessage sets the message returned by Message and
+// UserMessage for errors of the code which carry none. An empty
+// message removes the fallback of the code.
+func SetFallbackMessage(code, message string) {
+	fallbackMu.Lock()
+	defer fallbackMu.Unlock()
+	if message == "" {
+		delete(fallbackMessages, code)
+		return
+	}
+	fallbackMessages[code] = message
+}
+
+// FallbackMessage returns the fallback message of the code or of
+// its nearest parent, set by SetFallbackMessage or registered
+// with RegisterCode. It returns GlobalError if there is none.
+func FallbackMessage(code string) string {
+	for c := code; c != ""; c = ParentCode(c) {
+		fallbackMu.RLock()
+		msg, ok := fallbackMessages[c]
+		fallbackMu.RUnlock()
+		if ok {
+			return msg
+		}
+		if info, ok := lookupCode(c); ok && info.Message != "" {
+			return info.Message
+		}
+	}
+	return GlobalError
+}
+
+// UserMessage returns a message of err suitable for end users.
+// Internal errors never expose their message and get the
+// fallback message of their code instead.
+func UserMessage(err error) string {
+	if IsNil(err) {
+		return ""
+	}
+	if e, ok := err.(*Error); ok && e.Internal || Code(err) == INTERNAL {
+		return FallbackMessage(Code(err))
+	}
+	return Message(err)
+}
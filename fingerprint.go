@@ -0,0 +1,25 @@
+package errors
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"strconv"
+)
+
+// Fingerprint returns a stable identifier grouping occurrences
+// of the same error. It is derived from the code, operation and
+// the location where the error was created, falling back to the
+// message when no location is known.
+func (e *Error) Fingerprint() string {
+	if e == nil {
+		return ""
+	}
+	h := sha1.New()
+	h.Write([]byte(e.Code + "\x00" + e.Operation + "\x00"))
+	if c := e.Caller(); !c.IsZero() {
+		h.Write([]byte(c.Function + "\x00" + c.File + ":" + strconv.Itoa(c.Line)))
+	} else {
+		h.Write([]byte(e.Message))
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
@@ -0,0 +1,34 @@
+package errors_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/oarkflow/errors"
+)
+
+func wrapRead(err error) *errors.Error {
+	return errors.Wrap(err, "read", "file.Read").(*errors.Error)
+}
+
+func wrapOpen(err error) *errors.Error {
+	return errors.Wrap(err, "open", "file.Read").(*errors.Error)
+}
+
+func TestFingerprintCallSite(t *testing.T) {
+	a, b := wrapRead(io.EOF), wrapRead(io.ErrUnexpectedEOF)
+	if a.Fingerprint() != b.Fingerprint() {
+		t.Errorf("same call site: %s != %s", a.Fingerprint(), b.Fingerprint())
+	}
+	if c := wrapOpen(io.EOF); c.Fingerprint() == a.Fingerprint() {
+		t.Errorf("different call sites share fingerprint %s", a.Fingerprint())
+	}
+}
+
+func TestFingerprintMessageFallback(t *testing.T) {
+	a := &errors.Error{Code: errors.INVALID, Message: "a"}
+	b := &errors.Error{Code: errors.INVALID, Message: "b"}
+	if a.Fingerprint() == b.Fingerprint() {
+		t.Errorf("errors without location share fingerprint %s", a.Fingerprint())
+	}
+}
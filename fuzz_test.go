@@ -0,0 +1,126 @@
+package errors_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/oarkflow/errors"
+)
+
+func seedErrors() []*errors.Error {
+	return []*errors.Error{
+		errors.NewNotFound(nil, "user not found", "users.get"),
+		errors.NewInvalid(errors.New("bad input"), "invalid request", "users.create").
+			WithField("field", "email").
+			WithField("attempts", 3),
+		errors.NewInternal(nil, "", ""),
+	}
+}
+
+func seedJSON(f *testing.F) {
+	for _, e := range seedErrors() {
+		b, err := json.Marshal(e)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(b)
+	}
+	f.Add([]byte(`{}`))
+	f.Add([]byte(`null`))
+	f.Add([]byte(`{"code":1}`))
+	f.Add([]byte(`{"metadata":{"a":{"b":[1,2,{"c":null}]}}}`))
+	f.Add([]byte(`[[[[[[[[[[[[[[[[[[[[`))
+}
+
+// checkRoundTrip asserts that a decoded error encodes to JSON
+// which decodes to an error with the same encoding.
+func checkRoundTrip(t *testing.T, e *errors.Error) {
+	t.Helper()
+	first, err := json.Marshal(e)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var again errors.Error
+	if err := json.Unmarshal(first, &again); err != nil {
+		t.Fatalf("Unmarshal(%s): %v", first, err)
+	}
+	second, err := json.Marshal(&again)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if !bytes.Equal(first, second) {
+		t.Fatalf("round trip mismatch:\n%s\n%s", first, second)
+	}
+}
+
+func FuzzUnmarshalJSON(f *testing.F) {
+	seedJSON(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var e errors.Error
+		if err := e.UnmarshalJSON(data); err != nil {
+			return
+		}
+		checkRoundTrip(t, &e)
+	})
+}
+
+func FuzzScan(f *testing.F) {
+	seedJSON(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var fromBytes, fromString errors.Error
+		bErr := fromBytes.Scan(data)
+		sErr := fromString.Scan(string(data))
+		if (bErr == nil) != (sErr == nil) {
+			t.Fatalf("Scan([]byte) = %v, Scan(string) = %v", bErr, sErr)
+		}
+		if bErr != nil {
+			return
+		}
+		checkRoundTrip(t, &fromBytes)
+	})
+}
+
+func FuzzDecodeBinary(f *testing.F) {
+	for _, e := range seedErrors() {
+		b, err := errors.EncodeBinary(e, true)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(b)
+	}
+	f.Add([]byte{0})
+	f.Add([]byte{})
+	f.Add([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01})
+	f.Add([]byte{5, 1, 10, 'a'})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		e, n, err := errors.DecodeBinary(data)
+		if err != nil {
+			return
+		}
+		if n <= 0 || n > len(data) {
+			t.Fatalf("DecodeBinary read %d bytes of %d", n, len(data))
+		}
+		if e == nil {
+			return
+		}
+		b, err := errors.EncodeBinary(e, true)
+		if err != nil {
+			t.Fatalf("EncodeBinary: %v", err)
+		}
+		again, m, err := errors.DecodeBinary(b)
+		if err != nil {
+			t.Fatalf("DecodeBinary(EncodeBinary): %v", err)
+		}
+		if m != len(b) {
+			t.Fatalf("DecodeBinary read %d bytes of %d", m, len(b))
+		}
+		b2, err := errors.EncodeBinary(again, true)
+		if err != nil {
+			t.Fatalf("EncodeBinary: %v", err)
+		}
+		if !bytes.Equal(b, b2) {
+			t.Fatalf("binary round trip mismatch:\n%x\n%x", b, b2)
+		}
+	})
+}
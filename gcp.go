@@ -0,0 +1,119 @@
+package errors
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GCPEventType is the @type value which makes Cloud Logging
+// forward a JSON log entry to Cloud Error Reporting.
+const GCPEventType = "type.googleapis.com/google.devtools.clouderrorreporting.v1beta1.ReportedErrorEvent"
+
+// GCPServiceContext identifies the service reporting errors.
+type GCPServiceContext struct {
+	Service string `json:"service"`
+	Version string `json:"version,omitempty"`
+}
+
+// GCPHTTPRequest is the HTTP request context of an error event.
+type GCPHTTPRequest struct {
+	Method             string `json:"method,omitempty"`
+	URL                string `json:"url,omitempty"`
+	UserAgent          string `json:"userAgent,omitempty"`
+	Referrer           string `json:"referrer,omitempty"`
+	ResponseStatusCode int    `json:"responseStatusCode,omitempty"`
+	RemoteIP           string `json:"remoteIp,omitempty"`
+}
+
+// GCPReportLocation is the source location of an error event.
+type GCPReportLocation struct {
+	FilePath     string `json:"filePath,omitempty"`
+	LineNumber   int    `json:"lineNumber,omitempty"`
+	FunctionName string `json:"functionName,omitempty"`
+}
+
+// GCPErrorContext is the context of an error event.
+type GCPErrorContext struct {
+	HTTPRequest    *GCPHTTPRequest    `json:"httpRequest,omitempty"`
+	User           string             `json:"user,omitempty"`
+	ReportLocation *GCPReportLocation `json:"reportLocation,omitempty"`
+}
+
+// GCPErrorEvent is the Cloud Error Reporting ReportedErrorEvent
+// structure.
+type GCPErrorEvent struct {
+	Type           string            `json:"@type"`
+	EventTime      string            `json:"eventTime"`
+	ServiceContext GCPServiceContext `json:"serviceContext"`
+	Message        string            `json:"message"`
+	Context        *GCPErrorContext  `json:"context,omitempty"`
+}
+
+// ToGCPEvent converts the error to a Cloud Error Reporting
+// event. The message holds the error followed by its stack in
+// the goroutine dump format expected by the Go parser of Error
+// Reporting. The request, if any, or else the snapshot recorded by
+// WithRequest is added as HTTP context, with the status of the
+// snapshot recorded by WithResponse.
+func (e *Error) ToGCPEvent(service GCPServiceContext, r *http.Request) GCPErrorEvent {
+	if e == nil {
+		return GCPErrorEvent{}
+	}
+	event := GCPErrorEvent{
+		Type:           GCPEventType,
+		EventTime:      currentTime().UTC().Format(time.RFC3339Nano),
+		ServiceContext: service,
+		Message:        e.Error(),
+		Context:        &GCPErrorContext{},
+	}
+	if e.HasStack() {
+		event.Message += "\n\n" + e.gcpStack()
+	}
+	if len(e.Additional) > 0 {
+		t := e.Additional[0]
+		event.Context.ReportLocation = &GCPReportLocation{
+			FilePath:     t.File,
+			LineNumber:   t.Line,
+			FunctionName: t.Function,
+		}
+	}
+	if r != nil {
+		req := &GCPHTTPRequest{
+			Method:             r.Method,
+			UserAgent:          r.UserAgent(),
+			Referrer:           r.Referer(),
+			ResponseStatusCode: e.HTTPStatusCode(),
+			RemoteIP:           r.RemoteAddr,
+		}
+		if r.URL != nil {
+			req.URL = r.URL.String()
+		}
+		event.Context.HTTPRequest = req
+	} else if s, ok := RequestSnapshot(e); ok {
+		event.Context.HTTPRequest = &GCPHTTPRequest{
+			Method:             s.Method,
+			URL:                s.URL,
+			UserAgent:          s.Headers["User-Agent"],
+			Referrer:           s.Headers["Referer"],
+			ResponseStatusCode: e.HTTPStatusCode(),
+			RemoteIP:           s.RemoteAddr,
+		}
+	}
+	if s, ok := ResponseSnapshot(e); ok && event.Context.HTTPRequest != nil {
+		event.Context.HTTPRequest.ResponseStatusCode = s.Status
+	}
+	return event
+}
+
+// gcpStack renders the stack in the runtime/debug.Stack format.
+func (e *Error) gcpStack() string {
+	var buf strings.Builder
+	buf.WriteString("goroutine 1 [running]:\n")
+	for _, t := range e.Additional {
+		buf.WriteString(t.Function + "(...)\n")
+		buf.WriteString("\t" + t.File + ":" + strconv.Itoa(t.Line) + "\n")
+	}
+	return buf.String()
+}
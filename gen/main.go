@@ -0,0 +1,137 @@
+// Command gen generates typed constructors and registry code for
+// the application error codes described in a YAML or JSON spec.
+//
+// Usage:
+//
+//	//go:generate go run github.com/oarkflow/errors/gen -spec errors.yaml -out errors_gen.go
+//
+// A spec looks like:
+//
+//	package: users
+//	codes:
+//	  - name: UserNotFound
+//	    code: user.not_found
+//	    message: User not found.
+//	    http_status: 404
+//	    doc_url: https://docs.example.com/errors/user-not-found
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/token"
+	"os"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Spec is the description of the codes of a package.
+type Spec struct {
+	Package string     `yaml:"package"`
+	Codes   []CodeSpec `yaml:"codes"`
+}
+
+// CodeSpec describes a single code.
+type CodeSpec struct {
+	Name       string `yaml:"name"`
+	Code       string `yaml:"code"`
+	Message    string `yaml:"message"`
+	HTTPStatus int    `yaml:"http_status"`
+	DocURL     string `yaml:"doc_url"`
+}
+
+func main() {
+	specPath := flag.String("spec", "errors.yaml", "path of the YAML or JSON spec")
+	out := flag.String("out", "errors_gen.go", "path of the generated file")
+	pkg := flag.String("package", "", "package name, overriding the spec")
+	flag.Parse()
+	if err := run(*specPath, *out, *pkg); err != nil {
+		fmt.Fprintln(os.Stderr, "gen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(specPath, out, pkg string) error {
+	data, err := os.ReadFile(specPath)
+	if err != nil {
+		return err
+	}
+	var spec Spec
+	// JSON is a subset of YAML, so a single decoder serves both.
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return fmt.Errorf("parse %s: %w", specPath, err)
+	}
+	if pkg != "" {
+		spec.Package = pkg
+	}
+	if err := validate(spec); err != nil {
+		return err
+	}
+	src, err := generate(spec)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(out, src, 0o644)
+}
+
+func validate(spec Spec) error {
+	if !token.IsIdentifier(spec.Package) {
+		return fmt.Errorf("invalid package name %q", spec.Package)
+	}
+	seen := make(map[string]bool)
+	for _, c := range spec.Codes {
+		if !token.IsIdentifier(c.Name) {
+			return fmt.Errorf("invalid name %q", c.Name)
+		}
+		if c.Code == "" {
+			return fmt.Errorf("%s: missing code", c.Name)
+		}
+		if seen[c.Name] {
+			return fmt.Errorf("duplicate name %q", c.Name)
+		}
+		seen[c.Name] = true
+	}
+	return nil
+}
+
+var tmpl = template.Must(template.New("gen").Parse(`// Code generated by github.com/oarkflow/errors/gen. DO NOT EDIT.
+
+package {{.Package}}
+
+import "github.com/oarkflow/errors"
+
+// Application error codes.
+const (
+{{- range .Codes}}
+	// Code{{.Name}} - {{.Message}}
+	Code{{.Name}} = {{printf "%q" .Code}}
+{{- end}}
+)
+
+func init() {
+{{- range .Codes}}
+	errors.RegisterCode(errors.CodeInfo{
+		Code:       Code{{.Name}},
+		HTTPStatus: {{.HTTPStatus}},
+		Message:    {{printf "%q" .Message}},
+		DocURL:     {{printf "%q" .DocURL}},
+	})
+{{- end}}
+}
+{{range .Codes}}
+// Err{{.Name}} returns an Error with a Code{{.Name}} error code.
+func Err{{.Name}}(err error, op string) *errors.Error {
+	return errors.NewSkip(1, err, {{printf "%q" .Message}}, Code{{.Name}}, op)
+}
+{{end}}`))
+
+func generate(spec Spec) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, spec); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
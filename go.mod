@@ -1,3 +1,10 @@
 module github.com/oarkflow/errors
 
-go 1.19
+go 1.20
+
+require gopkg.in/yaml.v3 v3.0.1
+
+require (
+	github.com/kr/pretty v0.1.0 // indirect
+	gopkg.in/check.v1 v1.0.0-20190902080502-41f04d3bba15 // indirect
+)
@@ -0,0 +1,11 @@
+module github.com/oarkflow/errors/gqlerrors
+
+go 1.20
+
+require (
+	github.com/99designs/gqlgen v0.17.31
+	github.com/oarkflow/errors v0.0.0
+	github.com/vektah/gqlparser/v2 v2.5.1
+)
+
+replace github.com/oarkflow/errors => ../
@@ -0,0 +1,39 @@
+// Package gqlerrors presents application errors returned by
+// gqlgen resolvers with GraphQL extensions.
+package gqlerrors
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/oarkflow/errors"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// ErrorPresenter is a graphql.ErrorPresenterFunc converting
+// application errors to GraphQL errors whose extensions carry
+// the code, operation and details, after being shaped by
+// errors.Outbound. Internal errors get their errors.UserMessage.
+// Other errors are handled by graphql.DefaultErrorPresenter.
+//
+//	srv.SetErrorPresenter(gqlerrors.ErrorPresenter)
+func ErrorPresenter(ctx context.Context, err error) *gqlerror.Error {
+	var e *errors.Error
+	if !errors.As(err, &e) {
+		return graphql.DefaultErrorPresenter(ctx, err)
+	}
+	e = errors.Outbound(e)
+	gqlErr := &gqlerror.Error{
+		Message:    errors.UserMessage(e),
+		Path:       graphql.GetPath(ctx),
+		Extensions: e.GraphQLExtensions(),
+	}
+	var src *gqlerror.Error
+	if errors.As(err, &src) {
+		if len(src.Path) > 0 {
+			gqlErr.Path = src.Path
+		}
+		gqlErr.Locations = src.Locations
+	}
+	return gqlErr
+}
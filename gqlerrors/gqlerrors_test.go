@@ -0,0 +1,30 @@
+package gqlerrors_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/oarkflow/errors"
+	"github.com/oarkflow/errors/gqlerrors"
+)
+
+func TestErrorPresenterOutbound(t *testing.T) {
+	prev := errors.DefaultOutbound
+	errors.DefaultOutbound = &errors.OutboundPolicy{DropMetadata: []string{"query"}}
+	defer func() { errors.DefaultOutbound = prev }()
+
+	e := errors.NewInternal(nil, "select users failed", "users.List", true).
+		WithField("query", "SELECT * FROM users")
+	gqlErr := gqlerrors.ErrorPresenter(context.Background(), e)
+	if gqlErr.Message == "select users failed" {
+		t.Errorf("message = %q, the internal message", gqlErr.Message)
+	}
+	if details, ok := gqlErr.Extensions["details"].(map[string]any); ok {
+		if _, ok := details["query"]; ok {
+			t.Errorf("extensions hold the dropped metadata: %v", details)
+		}
+	}
+	if got, _ := e.Meta("query"); got == nil {
+		t.Error("ErrorPresenter changed the error")
+	}
+}
@@ -0,0 +1,42 @@
+package errors
+
+// GraphQLError is an error in the shape defined by the GraphQL
+// specification.
+type GraphQLError struct {
+	Message    string         `json:"message"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+// ToGraphQL converts the error to the GraphQL error shape. The
+// extensions hold the code, the operation and, as details, the
+// metadata and field violations of the error.
+func (e *Error) ToGraphQL(path []any) GraphQLError {
+	return GraphQLError{
+		Message:    Message(e),
+		Path:       path,
+		Extensions: e.GraphQLExtensions(),
+	}
+}
+
+// GraphQLExtensions returns the GraphQL extensions of the error.
+func (e *Error) GraphQLExtensions() map[string]any {
+	if e == nil {
+		return nil
+	}
+	ext := map[string]any{"code": Code(e)}
+	if e.Operation != "" {
+		ext["operation"] = e.Operation
+	}
+	details := map[string]any{}
+	for k, v := range e.metadata() {
+		details[k] = v
+	}
+	if violations := Violations(e); len(violations) > 0 {
+		details["violations"] = violations
+	}
+	if len(details) > 0 {
+		ext["details"] = details
+	}
+	return ext
+}
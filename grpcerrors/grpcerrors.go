@@ -0,0 +1,225 @@
+// Package grpcerrors converts application errors to and from
+// gRPC statuses and provides interceptors doing so on servers
+// and clients.
+package grpcerrors
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/oarkflow/errors"
+	"github.com/oarkflow/errors/internal/rpccode"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/runtime/protoiface"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Domain is the ErrorInfo domain identifying details produced
+// by this package.
+const Domain = "github.com/oarkflow/errors"
+
+// MetaOperation is the ErrorInfo metadata key of the operation.
+const MetaOperation = "operation"
+
+// ToStatus converts err to a gRPC status. Errors already carrying
+// a status are returned as is. Application errors are mapped to
+// the matching gRPC code, with their code, operation and metadata
+// sent as an ErrorInfo detail and their field violations as a
+// BadRequest detail, after being shaped by errors.Outbound. A nil
+// error returns a nil status.
+func ToStatus(err error) *status.Status {
+	if err == nil {
+		return nil
+	}
+	var e *errors.Error
+	if !errors.As(err, &e) {
+		if st, ok := status.FromError(err); ok {
+			return st
+		}
+		e = errors.From(err)
+	}
+	e = errors.Outbound(e)
+	st := status.New(codes.Code(rpccode.FromCode(errors.Code(e))), errors.Message(e))
+	info := &errdetails.ErrorInfo{
+		Reason:   errors.Code(e),
+		Domain:   Domain,
+		Metadata: map[string]string{},
+	}
+	for k, v := range e.Fields() {
+		if k != errors.MetaRetryAfter {
+			info.Metadata[k] = fmt.Sprint(v)
+		}
+	}
+	if e.Operation != "" {
+		info.Metadata[MetaOperation] = e.Operation
+	}
+	details := []protoiface.MessageV1{info}
+	if violations := errors.Violations(e); len(violations) > 0 {
+		br := &errdetails.BadRequest{}
+		for _, v := range violations {
+			br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       v.Field,
+				Description: v.Message,
+			})
+		}
+		details = append(details, br)
+	}
+	if d, ok := errors.RetryAfter(e); ok {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(d)})
+	}
+	if withDetails, dErr := st.WithDetails(details...); dErr == nil {
+		st = withDetails
+	}
+	return st
+}
+
+// FromStatus converts a gRPC status to an Error. The code is
+// taken from an ErrorInfo detail produced by ToStatus or reverse
+// mapped from the gRPC code, and the details are unpacked into
+// the operation, metadata and field violations. A nil or OK
+// status returns nil.
+func FromStatus(st *status.Status) *errors.Error {
+	if st == nil || st.Code() == codes.OK {
+		return nil
+	}
+	return fromStatus(st, "")
+}
+
+func fromStatus(st *status.Status, op string) *errors.Error {
+	code := rpccode.ToCode(uint32(st.Code()))
+	metadata := map[string]string{}
+	var violations []errors.FieldViolation
+	var retryAfter *time.Duration
+	for _, d := range st.Details() {
+		switch detail := d.(type) {
+		case *errdetails.ErrorInfo:
+			if detail.GetDomain() == Domain && detail.GetReason() != "" {
+				code = detail.GetReason()
+			}
+			for k, v := range detail.GetMetadata() {
+				metadata[k] = v
+			}
+		case *errdetails.RetryInfo:
+			if detail.GetRetryDelay() != nil {
+				d := detail.GetRetryDelay().AsDuration()
+				retryAfter = &d
+			}
+		case *errdetails.BadRequest:
+			for _, v := range detail.GetFieldViolations() {
+				violations = append(violations, errors.FieldViolation{
+					Field:   v.GetField(),
+					Message: v.GetDescription(),
+				})
+			}
+		}
+	}
+	if o, ok := metadata[MetaOperation]; ok {
+		op = o
+		delete(metadata, MetaOperation)
+	}
+	e := errors.NewSkip(2, st.Err(), st.Message(), code, op)
+	for k, v := range metadata {
+		e.WithField(k, v)
+	}
+	e.Violations = violations
+	if retryAfter != nil {
+		// The server asked for the call to be retried later.
+		e.Retryable = true
+		e.WithRetryAfter(*retryAfter)
+	}
+	return e
+}
+
+// FromError converts an error returned by a gRPC call to an
+// Error. Errors without a status are promoted with errors.From.
+func FromError(err error) *errors.Error {
+	if err == nil {
+		return nil
+	}
+	if st, ok := status.FromError(err); ok {
+		return fromStatus(st, "")
+	}
+	return errors.From(err)
+}
+
+// UnaryServerInterceptor converts the errors returned by unary
+// handlers to gRPC statuses with ToStatus.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, ToStatus(err).Err()
+		}
+		return resp, nil
+	}
+}
+
+// StreamServerInterceptor converts the errors returned by stream
+// handlers to gRPC statuses with ToStatus.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := handler(srv, ss); err != nil {
+			return ToStatus(err).Err()
+		}
+		return nil
+	}
+}
+
+// UnaryClientInterceptor converts the statuses returned by unary
+// calls to Errors. The gRPC method is used as the operation when
+// the status does not carry one.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if err := invoker(ctx, method, req, reply, cc, opts...); err != nil {
+			return convert(err, method)
+		}
+		return nil
+	}
+}
+
+// StreamClientInterceptor converts the statuses returned when
+// opening and using client streams to Errors. io.EOF is passed
+// through unchanged.
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, convert(err, method)
+		}
+		return &clientStream{ClientStream: cs, method: method}, nil
+	}
+}
+
+type clientStream struct {
+	grpc.ClientStream
+	method string
+}
+
+func (s *clientStream) SendMsg(m any) error {
+	return convert(s.ClientStream.SendMsg(m), s.method)
+}
+
+func (s *clientStream) RecvMsg(m any) error {
+	return convert(s.ClientStream.RecvMsg(m), s.method)
+}
+
+func (s *clientStream) CloseSend() error {
+	return convert(s.ClientStream.CloseSend(), s.method)
+}
+
+// convert returns err as an Error, keeping nil and io.EOF as is.
+func convert(err error, method string) error {
+	if err == nil || err == io.EOF {
+		return err
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return errors.From(err).WithOp(method)
+	}
+	return fromStatus(st, method)
+}
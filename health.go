@@ -0,0 +1,177 @@
+package errors
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// HealthTracker marks named dependencies unhealthy when the
+// errors they match reach a threshold within a window, and
+// healthy again after a quiet period without such errors. Feed
+// it with Observe, for instance from the
+// DefaultErrorCallbackHandler, and mount it as the /healthz
+// handler.
+type HealthTracker struct {
+	// Threshold is the number of matching errors within Window
+	// making a dependency unhealthy. Values below 1 mean 1.
+	Threshold int
+	// Window is the period over which errors are counted.
+	Window time.Duration
+	// Quiet is the period without matching errors after which an
+	// unhealthy dependency recovers.
+	Quiet time.Duration
+
+	mu   sync.Mutex
+	deps map[string]*dependency
+}
+
+type dependency struct {
+	rules     []Rule
+	seen      []time.Time
+	lastError time.Time
+	unhealthy bool
+}
+
+// DependencyHealth is the state of a dependency.
+type DependencyHealth struct {
+	Healthy   bool       `json:"healthy"`
+	Errors    int        `json:"errors"`
+	LastError *time.Time `json:"last_error,omitempty"`
+}
+
+// NewHealthTracker returns a HealthTracker with the given
+// threshold, window and quiet period.
+func NewHealthTracker(threshold int, window, quiet time.Duration) *HealthTracker {
+	return &HealthTracker{Threshold: threshold, Window: window, Quiet: quiet}
+}
+
+// Watch makes the errors matched by the code, operation and
+// Match criteria of r count against the dependency. Only these
+// criteria of the rule are used. A dependency may be watched
+// with several rules.
+func (h *HealthTracker) Watch(name string, r Rule) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.deps == nil {
+		h.deps = make(map[string]*dependency)
+	}
+	d, ok := h.deps[name]
+	if !ok {
+		d = &dependency{}
+		h.deps[name] = d
+	}
+	d.rules = append(d.rules, r)
+}
+
+// Observe counts err against the dependencies it matches. It
+// can be used as an ErrorCallbackHandler.
+func (h *HealthTracker) Observe(err *Error) {
+	if err == nil {
+		return
+	}
+	now := currentTime()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, d := range h.deps {
+		for _, r := range d.rules {
+			if r.matches(err) {
+				d.record(now, h.Window, h.Threshold)
+				break
+			}
+		}
+	}
+}
+
+func (d *dependency) record(now time.Time, window time.Duration, threshold int) {
+	d.seen = append(d.prune(now, window), now)
+	d.lastError = now
+	if threshold < 1 {
+		threshold = 1
+	}
+	if len(d.seen) >= threshold {
+		d.unhealthy = true
+	}
+}
+
+// prune drops the errors seen before the window.
+func (d *dependency) prune(now time.Time, window time.Duration) []time.Time {
+	i := 0
+	for i < len(d.seen) && now.Sub(d.seen[i]) > window {
+		i++
+	}
+	d.seen = d.seen[i:]
+	return d.seen
+}
+
+func (d *dependency) health(now time.Time, window, quiet time.Duration) DependencyHealth {
+	if d.unhealthy && now.Sub(d.lastError) >= quiet {
+		d.unhealthy = false
+	}
+	health := DependencyHealth{
+		Healthy: !d.unhealthy,
+		Errors:  len(d.prune(now, window)),
+	}
+	if !d.lastError.IsZero() {
+		last := d.lastError
+		health.LastError = &last
+	}
+	return health
+}
+
+// Status returns the state of every watched dependency.
+func (h *HealthTracker) Status() map[string]DependencyHealth {
+	now := currentTime()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	status := make(map[string]DependencyHealth, len(h.deps))
+	for name, d := range h.deps {
+		status[name] = d.health(now, h.Window, h.Quiet)
+	}
+	return status
+}
+
+// Healthy reports whether every watched dependency is healthy.
+func (h *HealthTracker) Healthy() bool {
+	for _, s := range h.Status() {
+		if !s.Healthy {
+			return false
+		}
+	}
+	return true
+}
+
+// Unhealthy returns the sorted names of the unhealthy
+// dependencies.
+func (h *HealthTracker) Unhealthy() []string {
+	var names []string
+	for name, s := range h.Status() {
+		if !s.Healthy {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ServeHTTP writes the state of the dependencies as JSON, with a
+// 503 status code if any of them is unhealthy.
+func (h *HealthTracker) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	status := h.Status()
+	code, state := http.StatusOK, "ok"
+	for _, s := range status {
+		if !s.Healthy {
+			code, state = http.StatusServiceUnavailable, "unhealthy"
+			break
+		}
+	}
+	w.Header().Set("Content-Type", MediaJSON)
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(struct {
+		Status       string                      `json:"status"`
+		Dependencies map[string]DependencyHealth `json:"dependencies"`
+	}{state, status})
+}
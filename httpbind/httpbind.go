@@ -0,0 +1,152 @@
+// Package httpbind decodes HTTP request bodies and parameters,
+// returning INVALID application errors holding field violations
+// so that handlers answer with consistent 400 responses.
+package httpbind
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/oarkflow/errors"
+)
+
+// MaxBodyBytes limits the size of the bodies read by DecodeJSON.
+// Zero or less disables the limit.
+var MaxBodyBytes int64 = 1 << 20
+
+// DisallowUnknownFields makes DecodeJSON reject objects holding
+// fields missing from the destination.
+var DisallowUnknownFields = false
+
+// DecodeJSON decodes the JSON body of r into v. Malformed bodies,
+// type mismatches and trailing data yield an INVALID Error
+// reporting the offending field, or "body" for the whole body.
+func DecodeJSON(r *http.Request, v any) error {
+	if r == nil || r.Body == nil || r.Body == http.NoBody {
+		return invalid(1, "body", "required", "", "request body is required", nil)
+	}
+	var body io.Reader = r.Body
+	if MaxBodyBytes > 0 {
+		body = io.LimitReader(r.Body, MaxBodyBytes+1)
+	}
+	lr := &countingReader{r: body}
+	dec := json.NewDecoder(lr)
+	if DisallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+	if err := dec.Decode(v); err != nil {
+		if MaxBodyBytes > 0 && lr.n > MaxBodyBytes {
+			return invalid(1, "body", "max", strconv.FormatInt(MaxBodyBytes, 10), "request body is too large", err)
+		}
+		if name, ok := unknownField(err); ok {
+			return invalid(1, name, "unknown", "", name+" is not a known field", err)
+		}
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field != "" && typeErr.Type != nil {
+			expected := typeErr.Type.String()
+			return invalid(1, typeErr.Field, "type", expected, typeErr.Field+" must be of type "+expected, err)
+		}
+		return invalid(1, "body", "json", "", "request body must be valid JSON", err)
+	}
+	if dec.More() {
+		return invalid(1, "body", "json", "", "request body must hold a single JSON value", nil)
+	}
+	return nil
+}
+
+// QueryString returns the query parameter name of r, or an
+// INVALID Error if it is missing or empty.
+func QueryString(r *http.Request, name string) (string, error) {
+	return queryString(1, r, name)
+}
+
+// queryString is QueryString, with the error located skip frames
+// above the caller of queryString.
+func queryString(skip int, r *http.Request, name string) (string, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return "", invalid(skip+1, name, "required", "", name+" is required", nil)
+	}
+	return v, nil
+}
+
+// QueryInt returns the query parameter name of r as an int, or an
+// INVALID Error if it is missing or not an integer.
+func QueryInt(r *http.Request, name string) (int, error) {
+	v, err := queryString(1, r, name)
+	if err != nil {
+		return 0, err
+	}
+	return parseInt(name, v)
+}
+
+// QueryIntDefault returns the query parameter name of r as an
+// int, or def if it is missing. A value which is not an integer
+// yields an INVALID Error.
+func QueryIntDefault(r *http.Request, name string, def int) (int, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return def, nil
+	}
+	return parseInt(name, v)
+}
+
+// QueryBool returns the query parameter name of r as a bool, or
+// false if it is missing. A value not accepted by
+// strconv.ParseBool yields an INVALID Error.
+func QueryBool(r *http.Request, name string) (bool, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, invalid(1, name, "boolean", "", name+" must be a boolean", err)
+	}
+	return b, nil
+}
+
+func parseInt(name, v string) (int, error) {
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, invalid(2, name, "integer", "", name+" must be an integer", err)
+	}
+	return n, nil
+}
+
+// invalid returns an INVALID Error wrapping err, located skip
+// frames above the caller of invalid.
+func invalid(skip int, field, rule, param, message string, err error) *errors.Error {
+	return errors.NewValidationSkip(skip+1, err, []errors.FieldViolation{{
+		Field:   field,
+		Rule:    rule,
+		Param:   param,
+		Message: message,
+	}}, message, "")
+}
+
+// unknownField extracts the field name of the error returned by
+// json.Decoder when DisallowUnknownFields is set.
+func unknownField(err error) (string, bool) {
+	const prefix = "json: unknown field "
+	msg := err.Error()
+	if !strings.HasPrefix(msg, prefix) {
+		return "", false
+	}
+	name, uErr := strconv.Unquote(strings.TrimPrefix(msg, prefix))
+	return name, uErr == nil
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
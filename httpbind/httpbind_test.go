@@ -0,0 +1,65 @@
+package httpbind_test
+
+import (
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/oarkflow/errors"
+	"github.com/oarkflow/errors/httpbind"
+)
+
+func TestInvalidLocation(t *testing.T) {
+	prev := errors.DefaultErrorCallbackHandler
+	notified := map[*errors.Error]int{}
+	errors.DefaultErrorCallbackHandler = func(e *errors.Error) { notified[e] = len(e.Violations) }
+	defer func() { errors.DefaultErrorCallbackHandler = prev }()
+
+	r := httptest.NewRequest("GET", "/?limit=ten&debug=maybe", nil)
+	_, intErr := httpbind.QueryInt(r, "limit")
+	_, missingIntErr := httpbind.QueryInt(r, "offset")
+	_, boolErr := httpbind.QueryBool(r, "debug")
+	_, strErr := httpbind.QueryString(r, "name")
+	jsonErr := httpbind.DecodeJSON(r, &struct{}{})
+	malformedErr := httpbind.DecodeJSON(httptest.NewRequest("POST", "/", strings.NewReader("{")), &struct{}{})
+	typeErr := httpbind.DecodeJSON(httptest.NewRequest("POST", "/", strings.NewReader(`{"age":"x"}`)), &struct {
+		Age int `json:"age"`
+	}{})
+
+	for name, err := range map[string]error{
+		"QueryInt": intErr, "QueryInt missing": missingIntErr, "QueryBool": boolErr, "QueryString": strErr,
+		"DecodeJSON": jsonErr, "DecodeJSON malformed": malformedErr, "DecodeJSON type": typeErr,
+	} {
+		var e *errors.Error
+		if !errors.As(err, &e) {
+			t.Errorf("%s returned %T", name, err)
+			continue
+		}
+		if e.Code != errors.INVALID {
+			t.Errorf("%s: code = %q", name, e.Code)
+		}
+		if fl := filepath.Base(e.FileLine()); !strings.HasPrefix(fl, "httpbind_test.go:") {
+			t.Errorf("%s: file line = %q, want httpbind_test.go", name, fl)
+		}
+		if n, ok := notified[e]; !ok || n != 1 {
+			t.Errorf("%s: notified %v with %d violations, want 1", name, ok, n)
+		}
+	}
+
+	var numErr *strconv.NumError
+	if !errors.As(intErr, &numErr) {
+		t.Errorf("QueryInt error does not wrap the strconv error: %v", intErr)
+	}
+}
+
+func TestDecodeJSONTypeViolation(t *testing.T) {
+	err := httpbind.DecodeJSON(httptest.NewRequest("POST", "/", strings.NewReader(`{"age":"x"}`)), &struct {
+		Age int `json:"age"`
+	}{})
+	v := errors.Violations(err)
+	if len(v) != 1 || v[0].Field != "age" || v[0].Rule != "type" || v[0].Param != "int" {
+		t.Errorf("violations = %+v", v)
+	}
+}
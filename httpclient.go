@@ -0,0 +1,243 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// maxResponseErrorBody is the maximum number of body bytes read by
+// FromResponse looking for an encoded error.
+const maxResponseErrorBody = 1 << 16
+
+// FromResponse converts a failed HTTP response, one whose status is
+// not 2xx, to an Error whose operation is the method and URL of the
+// request. An error encoded in the body, as written by the
+// Responder in JSON or problem+json, gives the code and message;
+// otherwise the code is derived from the status. 429 and 5xx
+// statuses other than 501 are retryable, and the Retry-After header
+// is recorded. The response is snapshotted as by WithResponse and
+// its body remains readable. A nil or successful response returns
+// nil.
+func FromResponse(resp *http.Response) *Error {
+	e := fromResponse(1, resp)
+	if e != nil {
+		notify(e)
+	}
+	return e
+}
+
+func fromResponse(skip int, resp *http.Response) *Error {
+	if resp == nil || resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	var body []byte
+	if resp.Body != nil && resp.Body != http.NoBody {
+		body, _ = io.ReadAll(io.LimitReader(resp.Body, maxResponseErrorBody))
+		resp.Body = restoredBody{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+	}
+	code, message := responseError(resp, body)
+	if code == "" {
+		code = statusCode(resp.StatusCode)
+	}
+	if message == "" {
+		message = http.StatusText(resp.StatusCode)
+	}
+	e := buildError(skip+1, nil, message, code, requestOp(resp.Request))
+	e.Retryable = resp.StatusCode == http.StatusTooManyRequests ||
+		resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented
+	if v := resp.Header.Get(HeaderRetryAfter); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+			e.WithRetryAfter(time.Duration(secs) * time.Second)
+		} else if t, err := http.ParseTime(v); err == nil {
+			e.WithRetryAt(t)
+		}
+	}
+	return e.WithResponse(resp)
+}
+
+// responseError returns the code and message of the error encoded in
+// the body, if any.
+func responseError(resp *http.Response, body []byte) (code, message string) {
+	if len(body) == 0 {
+		return "", ""
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch mediaType {
+	case MediaJSON:
+		var e struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(body, &e) == nil {
+			return e.Code, e.Message
+		}
+	case MediaProblem:
+		var p Problem
+		if json.Unmarshal(body, &p) == nil {
+			if p.Detail == "" {
+				p.Detail = p.Title
+			}
+			return p.Code, p.Detail
+		}
+	}
+	return "", ""
+}
+
+// statusCode returns the code of an HTTP status, the reverse of
+// HTTPStatusCode for the builtin codes.
+func statusCode(status int) string {
+	switch status {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return INVALID
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return FORBIDDEN
+	case http.StatusNotFound, http.StatusGone:
+		return NOTFOUND
+	case http.StatusConflict, http.StatusPreconditionFailed:
+		return CONFLICT
+	case http.StatusPaymentRequired:
+		return EXPIRED
+	case http.StatusTooManyRequests:
+		return MAXIMUMATTEMPTS
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return TIMEOUT
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return UNAVAILABLE
+	case http.StatusInsufficientStorage, http.StatusRequestEntityTooLarge:
+		return RESOURCEEXHAUSTED
+	}
+	if status >= 500 {
+		return INTERNAL
+	}
+	return UNKNOWN
+}
+
+// requestOp returns the method and the URL of r, without its
+// credentials and query.
+func requestOp(r *http.Request) string {
+	if r == nil || r.URL == nil {
+		return ""
+	}
+	u := url.URL{Scheme: r.URL.Scheme, Host: r.URL.Host, Path: r.URL.Path}
+	return r.Method + " " + u.String()
+}
+
+// ClientOption configures a Client returned by HTTPClient.
+type ClientOption func(*Client)
+
+// ClientRetry makes the Client retry the requests failing with a
+// retryable error, up to attempts attempts in total, waiting backoff
+// after the first failure, doubled after every further one, or the
+// Retry-After of the response if longer. Requests with a body are
+// only retried if their GetBody is set.
+func ClientRetry(attempts int, backoff time.Duration) ClientOption {
+	return func(c *Client) {
+		c.attempts, c.backoff = attempts, backoff
+	}
+}
+
+// ClientRetryIf replaces IsRetryable as the test of the failures
+// retried by the Client.
+func ClientRetryIf(fn func(err error) bool) ClientOption {
+	return func(c *Client) { c.retryIf = fn }
+}
+
+// Client is an HTTP client converting failures to Errors.
+type Client struct {
+	base     *http.Client
+	attempts int
+	backoff  time.Duration
+	retryIf  func(err error) bool
+}
+
+// HTTPClient returns a Client sending the requests with base, or
+// http.DefaultClient if nil.
+func HTTPClient(base *http.Client, opts ...ClientOption) *Client {
+	if base == nil {
+		base = http.DefaultClient
+	}
+	c := &Client{base: base, attempts: 1, retryIf: IsRetryable}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Do sends the request like http.Client.Do. Transport failures are
+// classified, timeouts being TIMEOUT errors, and responses whose
+// status is not 2xx are closed and converted with FromResponse. The
+// Errors have the method and URL of the request as operation.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	backoff := c.backoff
+	if backoff <= 0 {
+		backoff = 100 * time.Millisecond
+	}
+	for attempt := 1; ; attempt++ {
+		resp, e := c.send(req)
+		if e == nil {
+			return resp, nil
+		}
+		if attempt >= c.attempts || c.retryIf == nil || !c.retryIf(e) {
+			if attempt > 1 {
+				e.WithField(MetaAttempts, attempt)
+			}
+			notify(e)
+			return nil, e
+		}
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				notify(e)
+				return nil, e
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				notify(e)
+				return nil, e
+			}
+			req.Body = body
+		}
+		delay := backoff
+		if d, ok := RetryAfter(e); ok && d > delay {
+			delay = d
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			e.WithField(MetaAttempts, attempt)
+			notify(e)
+			return nil, e
+		}
+		backoff *= 2
+	}
+}
+
+// send sends one attempt of req, returning the Errors of the caller
+// of Do without notifying them.
+func (c *Client) send(req *http.Request) (*http.Response, *Error) {
+	resp, err := c.base.Do(req)
+	if err != nil {
+		var e *Error
+		if uErr, ok := err.(*url.Error); ok && uErr.Timeout() {
+			e = buildError(2, err, "", TIMEOUT, requestOp(req))
+			e.Retryable = true
+		} else {
+			e = classified(2, err, requestOp(req))
+		}
+		e.Context = req.Context()
+		return nil, e
+	}
+	if e := fromResponse(2, resp); e != nil {
+		resp.Body.Close()
+		e.Context = req.Context()
+		return nil, e
+	}
+	return resp, nil
+}
@@ -0,0 +1,178 @@
+package errors
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	// MetaHTTPRequest is the metadata key of the request snapshot
+	// recorded by WithRequest.
+	MetaHTTPRequest = "http_request"
+	// MetaHTTPResponse is the metadata key of the response snapshot
+	// recorded by WithResponse.
+	MetaHTTPResponse = "http_response"
+)
+
+var (
+	// SnapshotHeaders lists the headers recorded by WithRequest and
+	// WithResponse.
+	SnapshotHeaders = []string{
+		"Accept", "Authorization", "Content-Length", "Content-Type", "Cookie",
+		"Referer", "Retry-After", "Set-Cookie", "User-Agent", "X-Forwarded-For",
+		"X-Request-Id",
+	}
+	// RedactedHeaders lists the recorded headers whose values are
+	// replaced by SecretMask.
+	RedactedHeaders = []string{
+		"Authorization", "Cookie", "Proxy-Authorization", "Set-Cookie", "X-Api-Key",
+	}
+	// SnapshotBodyLimit is the maximum number of body bytes recorded
+	// by WithRequest and WithResponse. Bodies are not recorded when
+	// it is zero.
+	SnapshotBodyLimit = 0
+)
+
+// HTTPRequestSnapshot describes the HTTP request during which an
+// error occurred.
+type HTTPRequestSnapshot struct {
+	Method     string            `json:"method,omitempty"`
+	URL        string            `json:"url,omitempty"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       string            `json:"body,omitempty"`
+	Truncated  bool              `json:"truncated,omitempty"`
+	RemoteAddr string            `json:"remote_addr,omitempty"`
+}
+
+// HTTPResponseSnapshot describes the HTTP response which caused an
+// error.
+type HTTPResponseSnapshot struct {
+	Status    int               `json:"status"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Body      string            `json:"body,omitempty"`
+	Truncated bool              `json:"truncated,omitempty"`
+}
+
+// WithRequest records the method, URL, selected headers and, if
+// SnapshotBodyLimit allows, the beginning of the body of r, and
+// returns the error for chaining. The password of the URL and the
+// RedactedHeaders are masked. The body remains readable.
+func (e *Error) WithRequest(r *http.Request) *Error {
+	if e == nil || r == nil {
+		return e
+	}
+	s := &HTTPRequestSnapshot{
+		Method:     r.Method,
+		Headers:    snapshotHeaders(r.Header),
+		RemoteAddr: r.RemoteAddr,
+	}
+	if r.URL != nil {
+		s.URL = r.URL.Redacted()
+	}
+	if SnapshotBodyLimit > 0 {
+		body := r.Body
+		if r.GetBody != nil {
+			if b, err := r.GetBody(); err == nil {
+				body = b
+				defer b.Close()
+			}
+		}
+		if body != nil && body != http.NoBody {
+			var head []byte
+			head, s.Body, s.Truncated = readHead(body)
+			if body == r.Body {
+				r.Body = restoredBody{io.MultiReader(bytes.NewReader(head), body), body}
+			}
+		}
+	}
+	return e.WithField(MetaHTTPRequest, s)
+}
+
+// WithResponse records the status, selected headers and, if
+// SnapshotBodyLimit allows, the beginning of the body of resp, and
+// returns the error for chaining. The RedactedHeaders are masked.
+// The body remains readable.
+func (e *Error) WithResponse(resp *http.Response) *Error {
+	if e == nil || resp == nil {
+		return e
+	}
+	s := &HTTPResponseSnapshot{
+		Status:  resp.StatusCode,
+		Headers: snapshotHeaders(resp.Header),
+	}
+	if SnapshotBodyLimit > 0 && resp.Body != nil && resp.Body != http.NoBody {
+		var head []byte
+		head, s.Body, s.Truncated = readHead(resp.Body)
+		resp.Body = restoredBody{io.MultiReader(bytes.NewReader(head), resp.Body), resp.Body}
+	}
+	return e.WithField(MetaHTTPResponse, s)
+}
+
+// RequestSnapshot returns the request snapshot recorded by the
+// first Error of the chain having one.
+func RequestSnapshot(err error) (*HTTPRequestSnapshot, bool) {
+	for _, e := range chainOf(err) {
+		if s, ok := e.snapshot(MetaHTTPRequest).(*HTTPRequestSnapshot); ok {
+			return s, true
+		}
+	}
+	return nil, false
+}
+
+// ResponseSnapshot returns the response snapshot recorded by the
+// first Error of the chain having one.
+func ResponseSnapshot(err error) (*HTTPResponseSnapshot, bool) {
+	for _, e := range chainOf(err) {
+		if s, ok := e.snapshot(MetaHTTPResponse).(*HTTPResponseSnapshot); ok {
+			return s, true
+		}
+	}
+	return nil, false
+}
+
+func (e *Error) snapshot(key string) any {
+	v, _ := e.Meta(key)
+	return v
+}
+
+func snapshotHeaders(h http.Header) map[string]string {
+	var out map[string]string
+	for _, name := range SnapshotHeaders {
+		values := h.Values(name)
+		if len(values) == 0 {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]string)
+		}
+		key := http.CanonicalHeaderKey(name)
+		out[key] = strings.Join(values, ", ")
+		for _, redacted := range RedactedHeaders {
+			if strings.EqualFold(name, redacted) {
+				out[key] = SecretMask
+				break
+			}
+		}
+	}
+	return out
+}
+
+// readHead reads one byte past SnapshotBodyLimit bytes of r. It
+// returns the bytes read, to be replayed, the recorded body and
+// whether the body was truncated.
+func readHead(r io.Reader) ([]byte, string, bool) {
+	head, _ := io.ReadAll(io.LimitReader(r, int64(SnapshotBodyLimit)+1))
+	if len(head) > SnapshotBodyLimit {
+		return head, string(head[:SnapshotBodyLimit]), true
+	}
+	return head, string(head), false
+}
+
+// restoredBody replays the bytes read by readHead before the rest
+// of the original body, which it closes.
+type restoredBody struct {
+	io.Reader
+	io.Closer
+}
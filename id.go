@@ -0,0 +1,37 @@
+package errors
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// ID returns the unique identifier of the error, generating it on
+// first use. Once assigned, the ID is serialized with the error,
+// so it can be quoted by clients and searched in logs.
+func (e *Error) ID() string {
+	if e == nil {
+		return ""
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.id == "" {
+		e.id = newID()
+	}
+	return e.id
+}
+
+// assignedID returns the ID of the error, or "" if none was
+// generated yet.
+func (e *Error) assignedID() string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.id
+}
+
+func newID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,36 @@
+package errors
+
+// Ignore returns nil if any Error in the chain of err has one of
+// the codes, or a code descending from one of them. Otherwise, err
+// is returned unchanged.
+//
+//	if err := errors.Ignore(repo.Delete(id), errors.NOTFOUND); err != nil {
+//		return err
+//	}
+func Ignore(err error, codes ...string) error {
+	if err == nil {
+		return nil
+	}
+	for _, e := range chainOf(err) {
+		for _, code := range codes {
+			if e.Code != "" && hasCodePrefix(e.Code, code) {
+				return nil
+			}
+		}
+	}
+	return err
+}
+
+// IgnoreIs returns nil if err matches any of the targets with Is.
+// Otherwise, err is returned unchanged.
+func IgnoreIs(err error, targets ...error) error {
+	if err == nil {
+		return nil
+	}
+	for _, target := range targets {
+		if Is(err, target) {
+			return nil
+		}
+	}
+	return err
+}
@@ -0,0 +1,16 @@
+// Package hooks holds the clock and caller providers of the
+// errors package, replaced by errtest to make tests deterministic.
+package hooks
+
+import (
+	"runtime"
+	"time"
+)
+
+var (
+	// Now returns the current time.
+	Now = time.Now
+	// Caller reports the location of the caller skip frames above
+	// the caller of Caller, as runtime.Caller does.
+	Caller = runtime.Caller
+)
@@ -0,0 +1,78 @@
+// Package rpccode maps application error codes to the canonical
+// RPC status codes shared by gRPC and Connect.
+package rpccode
+
+import "github.com/oarkflow/errors"
+
+// Canonical RPC status codes.
+const (
+	OK                 uint32 = 0
+	Canceled           uint32 = 1
+	Unknown            uint32 = 2
+	InvalidArgument    uint32 = 3
+	DeadlineExceeded   uint32 = 4
+	NotFound           uint32 = 5
+	AlreadyExists      uint32 = 6
+	PermissionDenied   uint32 = 7
+	ResourceExhausted  uint32 = 8
+	FailedPrecondition uint32 = 9
+	Aborted            uint32 = 10
+	OutOfRange         uint32 = 11
+	Unimplemented      uint32 = 12
+	Internal           uint32 = 13
+	Unavailable        uint32 = 14
+	DataLoss           uint32 = 15
+	Unauthenticated    uint32 = 16
+)
+
+var toRPC = map[string]uint32{
+	errors.CONFLICT:          AlreadyExists,
+	errors.INTERNAL:          Internal,
+	errors.INVALID:           InvalidArgument,
+	errors.NOTFOUND:          NotFound,
+	errors.UNKNOWN:           Unknown,
+	errors.MAXIMUMATTEMPTS:   ResourceExhausted,
+	errors.EXPIRED:           FailedPrecondition,
+	errors.UNAVAILABLE:       Unavailable,
+	errors.FORBIDDEN:         PermissionDenied,
+	errors.TIMEOUT:           DeadlineExceeded,
+	errors.RESOURCEEXHAUSTED: ResourceExhausted,
+}
+
+var fromRPC = map[uint32]string{
+	Canceled:           errors.UNKNOWN,
+	Unknown:            errors.UNKNOWN,
+	InvalidArgument:    errors.INVALID,
+	OutOfRange:         errors.INVALID,
+	NotFound:           errors.NOTFOUND,
+	AlreadyExists:      errors.CONFLICT,
+	Aborted:            errors.CONFLICT,
+	ResourceExhausted:  errors.MAXIMUMATTEMPTS,
+	FailedPrecondition: errors.EXPIRED,
+	Internal:           errors.INTERNAL,
+	DataLoss:           errors.INTERNAL,
+	Unavailable:        errors.UNAVAILABLE,
+	PermissionDenied:   errors.FORBIDDEN,
+	DeadlineExceeded:   errors.TIMEOUT,
+}
+
+// FromCode returns the RPC status code of the application code,
+// falling back to the parents of hierarchical codes and finally
+// to Unknown.
+func FromCode(code string) uint32 {
+	for c := code; c != ""; c = errors.ParentCode(c) {
+		if rpc, ok := toRPC[c]; ok {
+			return rpc
+		}
+	}
+	return Unknown
+}
+
+// ToCode returns the application code of the RPC status code,
+// or INTERNAL when there is no equivalent.
+func ToCode(rpc uint32) string {
+	if code, ok := fromRPC[rpc]; ok {
+		return code
+	}
+	return errors.INTERNAL
+}
@@ -0,0 +1,52 @@
+package errors
+
+import (
+	"context"
+	"time"
+)
+
+// MetaDuration is the metadata key of the execution time of a Job
+// which failed.
+const MetaDuration = "duration"
+
+// JobReporter, if set, receives the error of every failed Job.
+// Otherwise, it is passed to the DefaultErrorCallbackHandler.
+var JobReporter Reporter
+
+// Job wraps fn, a cron or queue job, so that its failures are
+// never lost. The returned function times fn and recovers its
+// panics. If fn fails, its error is classified and wrapped in an
+// Error with the job name as operation and the execution time in
+// the metadata, which is reported and returned.
+func Job(name string, fn func(ctx context.Context) error) func(ctx context.Context) error {
+	return func(ctx context.Context) (err error) {
+		start := currentTime()
+		defer func() {
+			if r := recover(); r != nil {
+				err = panicError(r)
+			}
+			if err != nil {
+				err = jobError(ctx, name, err, currentTime().Sub(start))
+			}
+		}()
+		return fn(ctx)
+	}
+}
+
+func jobError(ctx context.Context, name string, err error, elapsed time.Duration) *Error {
+	var e *Error
+	if c := findError(err, 0, func(e *Error) bool { return e.Code != "" }); c != nil {
+		e = buildError(1, err, "", c.Code, name)
+		e.Retryable = IsRetryable(err)
+	} else {
+		e = classified(1, err, name)
+	}
+	e.WithField(MetaDuration, elapsed.Round(time.Millisecond).String())
+	e.Context = ctx
+	if JobReporter != nil {
+		_ = JobReporter.Report(ctx, e)
+	} else {
+		notify(e)
+	}
+	return e
+}
@@ -0,0 +1,99 @@
+package errors
+
+// JoinStrategy selects the branch of a joined error, one
+// implementing Unwrap() []error such as Multi or the errors
+// returned by the stdlib errors.Join, which Code, Message and
+// UserMessage report on.
+type JoinStrategy int
+
+const (
+	// JoinFirst picks the first branch holding an Error.
+	JoinFirst JoinStrategy = iota
+	// JoinLast picks the last branch holding an Error.
+	JoinLast
+	// JoinMostSevere picks the branch whose Error has the
+	// highest severity, the first one on ties.
+	JoinMostSevere
+)
+
+// DefaultJoinStrategy is the JoinStrategy used by Code and
+// Message.
+var DefaultJoinStrategy = JoinFirst
+
+// IsCode reports whether err, or any error it wraps including the
+// branches of joined errors, is an Error with the code.
+func IsCode(err error, code string) bool {
+	return walkTree(err, 0, func(e *Error) bool { return e.Code == code })
+}
+
+// walkTree calls fn with the Errors of the tree of err until fn
+// returns true.
+func walkTree(err error, depth int, fn func(e *Error) bool) bool {
+	for i := 0; err != nil && i < maxChainLength && depth < maxTreeDepth; i++ {
+		switch v := err.(type) {
+		case *Error:
+			if v == nil {
+				return false
+			}
+			if fn(v) {
+				return true
+			}
+			err = v.Err
+		case interface{ Unwrap() []error }:
+			for _, branch := range v.Unwrap() {
+				if walkTree(branch, depth+1, fn) {
+					return true
+				}
+			}
+			return false
+		default:
+			err = Unwrap(err)
+		}
+	}
+	return false
+}
+
+// findError returns the Error of the chain of err for which ok
+// returns true, descending into the branch of joined errors
+// chosen by DefaultJoinStrategy. Only Errors wrapping each other
+// directly are followed, as Code always did.
+func findError(err error, depth int, ok func(e *Error) bool) *Error {
+	for i := 0; err != nil && i < maxChainLength && depth < maxTreeDepth; i++ {
+		switch v := err.(type) {
+		case *Error:
+			if v == nil {
+				return nil
+			}
+			if ok(v) {
+				return v
+			}
+			err = v.Err
+		case interface{ Unwrap() []error }:
+			return pickBranch(v.Unwrap(), depth, ok)
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+func pickBranch(branches []error, depth int, ok func(e *Error) bool) *Error {
+	var picked *Error
+	for _, branch := range branches {
+		e := findError(branch, depth+1, ok)
+		if e == nil {
+			continue
+		}
+		switch DefaultJoinStrategy {
+		case JoinLast:
+			picked = e
+		case JoinMostSevere:
+			if picked == nil || SeverityOf(e) > SeverityOf(picked) {
+				picked = e
+			}
+		default:
+			return e
+		}
+	}
+	return picked
+}
@@ -0,0 +1,67 @@
+package errors
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Metadata keys set by FromJSONError.
+const (
+	MetaJSONOffset   = "offset"
+	MetaJSONField    = "field"
+	MetaJSONExpected = "expected"
+	MetaJSONValue    = "value"
+)
+
+// FromJSONError converts an encoding/json decode failure into an
+// Error with a INVALID error code. The offset, field and expected
+// type of the failure are stored in the metadata, and a type
+// mismatch on a field is also reported as a field violation.
+// Other errors are returned as by From.
+func FromJSONError(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	var (
+		syntaxErr  *json.SyntaxError
+		typeErr    *json.UnmarshalTypeError
+		invalidErr *json.InvalidUnmarshalError
+		e          *Error
+	)
+	switch {
+	case As(err, &syntaxErr):
+		e = buildError(1, err, "malformed JSON", INVALID, "")
+		e.WithField(MetaJSONOffset, syntaxErr.Offset)
+	case As(err, &typeErr):
+		e = buildError(1, err, "invalid JSON value", INVALID, "")
+		e.WithField(MetaJSONOffset, typeErr.Offset)
+		e.WithField(MetaJSONValue, typeErr.Value)
+		expected := ""
+		if typeErr.Type != nil {
+			expected = typeErr.Type.String()
+			e.WithField(MetaJSONExpected, expected)
+		}
+		if typeErr.Field != "" {
+			e.WithField(MetaJSONField, typeErr.Field)
+			e.Violations = []FieldViolation{{
+				Field:   typeErr.Field,
+				Rule:    "type",
+				Param:   expected,
+				Message: "must be of type " + expected,
+			}}
+		}
+	case As(err, &invalidErr):
+		e = buildError(1, err, "invalid JSON target", INVALID, "")
+		if invalidErr.Type != nil {
+			e.WithField(MetaJSONExpected, invalidErr.Type.String())
+		}
+	case Is(err, io.EOF):
+		e = buildError(1, err, "empty JSON body", INVALID, "")
+	case Is(err, io.ErrUnexpectedEOF):
+		e = buildError(1, err, "truncated JSON", INVALID, "")
+	default:
+		return from(1, err)
+	}
+	notify(e)
+	return e
+}
@@ -0,0 +1,65 @@
+package errors
+
+import (
+	"errors"
+	"regexp"
+)
+
+// LegacyPatterns are the patterns tried in order by ParseLegacy.
+// They use the named groups code, op, file_line, cause and message,
+// all optional but code. The defaults match the Error format, such
+// as "<not_found> repo.go:12 - users.Get: sql: no rows, user not
+// found", anywhere in a line, and the first line of
+// ErrorWithStackTrace.
+var LegacyPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`<(?P<code>[\w.:/-]+)> (?:(?P<file_line>\S+:\d+) - )?(?:(?P<op>[^:<]+?): )?(?:(?P<cause>.*), )?(?P<message>[^,]*)$`),
+	regexp.MustCompile(`(?m)Type: (?P<code>[\w.:/-]+), Message: (?P<message>.*), Operation: (?P<op>.*)$`),
+}
+
+// ParseLegacy reconstructs an Error from a plain-text log line
+// holding an error string, using the first matching LegacyPatterns,
+// so that logs written before structured logging was enabled can be
+// processed like structured ones. A cause which is itself an error
+// string is parsed into an Error. The parse is best-effort, since
+// the text format is ambiguous, e.g. a message containing a comma
+// is split into a cause and a message. The Error has no stack and
+// is not notified.
+func ParseLegacy(line string) (*Error, bool) {
+	return parseLegacy(line, 0)
+}
+
+func parseLegacy(line string, depth int) (*Error, bool) {
+	for _, re := range LegacyPatterns {
+		m := re.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		groups := make(map[string]string, len(m))
+		for i, name := range re.SubexpNames() {
+			if name != "" {
+				groups[name] = m[i]
+			}
+		}
+		if groups["code"] == "" {
+			continue
+		}
+		e := &Error{
+			Code:      groups["code"],
+			Message:   groups["message"],
+			Operation: groups["op"],
+			Internal:  groups["code"] == INTERNAL,
+			fileLine:  groups["file_line"],
+		}
+		e.caller = callerFromFileLine(e.fileLine)
+		if cause := groups["cause"]; cause != "" {
+			e.Err = errors.New(cause)
+			if depth+1 < maxTreeDepth {
+				if inner, ok := parseLegacy(cause, depth+1); ok {
+					e.Err = inner
+				}
+			}
+		}
+		return e, true
+	}
+	return nil, false
+}
@@ -0,0 +1,28 @@
+package errors_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/oarkflow/errors"
+)
+
+func TestParseLegacyDeepCause(t *testing.T) {
+	const levels = 2000
+	line := strings.Repeat("<internal> ", levels) + "root" + strings.Repeat(", failed", levels-1)
+	e, ok := errors.ParseLegacy(line)
+	if !ok {
+		t.Fatal("line not parsed")
+	}
+	depth := 0
+	for err := error(e); err != nil; depth++ {
+		inner, ok := err.(*errors.Error)
+		if !ok {
+			break
+		}
+		err = inner.Err
+	}
+	if depth > 64 {
+		t.Errorf("parsed %d nested errors, want at most 64", depth)
+	}
+}
@@ -0,0 +1,22 @@
+package errors
+
+// Light returns an Error with the given code that captures no
+// stack and does not call the DefaultErrorCallbackHandler. It
+// suits expected errors on hot paths, such as NOTFOUND lookups,
+// and costs a single allocation. Light errors still map to HTTP
+// status codes and marshal to JSON, without the stack fields.
+func Light(code, message, op string) *Error {
+	recordOp(op)
+	return &Error{
+		Code:      code,
+		Message:   message,
+		Operation: op,
+		Internal:  code == INTERNAL,
+	}
+}
+
+// HasStack reports whether a stack was captured for the error,
+// or decoded along with it.
+func (e *Error) HasStack() bool {
+	return e != nil && (len(e.pcs) > 0 || len(e.Additional) > 0)
+}
@@ -0,0 +1,139 @@
+package errors
+
+import (
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// LocaleData is the data of the templates registered with
+// RegisterLocale, rendering one Error of a chain.
+type LocaleData struct {
+	// Code is the label of the code in the locale, set with
+	// RegisterCodeLabel, or the code itself.
+	Code      string
+	Message   string
+	Operation string
+	FileLine  string
+	// Cause is the rendering of the cause, localized if it is an
+	// Error, or empty.
+	Cause string
+	// Err is the rendered Error.
+	Err *LocaleError
+}
+
+// LocaleError is the Error rendered by a locale template, holding
+// the same fields. Its Error method returns the error string not
+// localized, so that the templates can call it even when
+// DefaultLocale is set.
+type LocaleError Error
+
+// Error returns the error string of the Error, not localized.
+func (e *LocaleError) Error() string {
+	return (*Error)(e).errorString(0, true)
+}
+
+type locale struct {
+	tmpl   *template.Template
+	labels map[string]string
+}
+
+// DefaultLocale, if registered with RegisterLocale, is the locale
+// in which Error renders the errors, e.g. set by a CLI from the
+// environment at startup.
+var DefaultLocale string
+
+var (
+	localesMu sync.RWMutex
+	locales   = make(map[string]*locale)
+)
+
+// RegisterLocale registers the text/template rendering the errors
+// in the locale, such as "fr" or "pt-BR", with LocaleData. It is
+// meant to be called at startup:
+//
+//	errors.RegisterLocale("fr", `{{if .Code}}<{{.Code}}> {{end}}`+
+//		`{{if .Operation}}opération {{.Operation}} : {{end}}`+
+//		`{{if .Cause}}{{.Cause}}, {{end}}{{.Message}}`)
+func RegisterLocale(name, text string) error {
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return err
+	}
+	localesMu.Lock()
+	defer localesMu.Unlock()
+	if l, ok := locales[name]; ok {
+		l.tmpl = tmpl
+		return nil
+	}
+	locales[name] = &locale{tmpl: tmpl, labels: make(map[string]string)}
+	return nil
+}
+
+// RegisterCodeLabel sets the label of the code in the locale,
+// rendered as LocaleData.Code. The locale must be registered.
+func RegisterCodeLabel(name, code, label string) {
+	localesMu.Lock()
+	defer localesMu.Unlock()
+	if l, ok := locales[name]; ok {
+		l.labels[code] = label
+	}
+}
+
+// lookupLocale returns the locale registered for name, or for its
+// language, e.g. "pt" for "pt-BR".
+func lookupLocale(name string) (*locale, bool) {
+	localesMu.RLock()
+	defer localesMu.RUnlock()
+	if l, ok := locales[name]; ok {
+		return l, true
+	}
+	if i := strings.IndexAny(name, "-_"); i > 0 {
+		l, ok := locales[name[:i]]
+		return l, ok
+	}
+	return nil, false
+}
+
+// ErrorIn returns the error string rendered with the template of
+// the locale, or of its language. It returns Error when the locale
+// is not registered.
+func (e *Error) ErrorIn(name string) string {
+	l, ok := lookupLocale(name)
+	if !ok {
+		return e.Error()
+	}
+	return e.localize(l, 0)
+}
+
+func (e *Error) localize(l *locale, depth int) string {
+	if e == nil {
+		return ""
+	}
+	if depth >= maxTreeDepth {
+		return "..."
+	}
+	data := LocaleData{
+		Code:      e.Code,
+		Message:   e.Message,
+		Operation: e.Operation,
+		FileLine:  e.fileLine,
+		Err:       (*LocaleError)(e),
+	}
+	localesMu.RLock()
+	tmpl := l.tmpl
+	if label, ok := l.labels[e.Code]; ok {
+		data.Code = label
+	}
+	localesMu.RUnlock()
+	if inner, ok := e.Err.(*Error); ok && inner != nil {
+		data.Cause = inner.localize(l, depth+1)
+	} else if e.Err != nil {
+		data.Cause = e.Err.Error()
+	}
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return e.errorString(depth, true)
+	}
+	return strings.TrimSuffix(strings.TrimSpace(buf.String()), ",")
+}
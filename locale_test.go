@@ -0,0 +1,46 @@
+package errors_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/oarkflow/errors"
+)
+
+func TestLocaleErrCallsError(t *testing.T) {
+	if err := errors.RegisterLocale("xx-recurse", `localized: {{.Err.Error}}`); err != nil {
+		t.Fatal(err)
+	}
+	prev := errors.DefaultLocale
+	errors.DefaultLocale = "xx-recurse"
+	t.Cleanup(func() { errors.DefaultLocale = prev })
+
+	got := errors.NewNotFound(nil, "user not found", "users.get", true).Error()
+	if !strings.HasPrefix(got, "localized: <not_found>") || !strings.Contains(got, "user not found") {
+		t.Errorf("Error() = %q", got)
+	}
+}
+
+func TestRegisterLocaleConcurrent(t *testing.T) {
+	if err := errors.RegisterLocale("xx-race", `{{.Message}}`); err != nil {
+		t.Fatal(err)
+	}
+	e := errors.NewNotFound(nil, "user not found", "users.get", true)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			_ = errors.RegisterLocale("xx-race", `{{.Code}} {{.Message}}`)
+			errors.RegisterCodeLabel("xx-race", errors.NOTFOUND, "introuvable")
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			_ = e.ErrorIn("xx-race")
+		}
+	}()
+	wg.Wait()
+}
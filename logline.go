@@ -0,0 +1,94 @@
+package errors
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MetaTraceID is the metadata key holding the trace ID emitted
+// in log lines.
+const MetaTraceID = "trace_id"
+
+// logLine is the schema of LogLine. Its fields are stable and
+// emitted in this order.
+type logLine struct {
+	TS          string `json:"ts"`
+	Level       string `json:"level"`
+	Code        string `json:"code"`
+	Op          string `json:"op,omitempty"`
+	Msg         string `json:"msg"`
+	Cause       string `json:"cause,omitempty"`
+	Fingerprint string `json:"fingerprint"`
+	TraceID     string `json:"trace_id,omitempty"`
+	RequestID   string `json:"request_id,omitempty"`
+	Stack       string `json:"stack,omitempty"`
+}
+
+// LogLine returns the error as a single-line JSON object with a
+// stable schema suited to log pipelines such as Loki or ELK: ts,
+// level, code, op, msg, cause, fingerprint, trace_id, request_id
+// and the full stack, see StackFrames, as one string. It has no
+// trailing newline.
+func (e *Error) LogLine() string {
+	if e == nil {
+		return ""
+	}
+	l := logLine{
+		TS:          currentTime().UTC().Format(time.RFC3339Nano),
+		Level:       SeverityOf(e).String(),
+		Code:        e.Code,
+		Op:          e.Operation,
+		Msg:         Message(e),
+		Fingerprint: e.Fingerprint(),
+		Stack:       strings.TrimSuffix(StackTrace(e.StackFrames()).String(), "\n"),
+	}
+	if e.Err != nil {
+		l.Cause = e.Err.Error()
+	}
+	if v, ok := e.Meta(MetaTraceID); ok {
+		l.TraceID, _ = v.(string)
+	}
+	if v, ok := e.Meta(MetaRequestID); ok {
+		l.RequestID, _ = v.(string)
+	}
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// LogWriter appends the log lines of errors to a writer, one per
+// line. It is safe for concurrent use.
+type LogWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewLogWriter returns a LogWriter appending to w.
+func NewLogWriter(w io.Writer) *LogWriter {
+	return &LogWriter{w: w}
+}
+
+// Report writes the log line of the error. It implements
+// Reporter.
+func (lw *LogWriter) Report(_ context.Context, err *Error) error {
+	if err == nil {
+		return nil
+	}
+	line := err.LogLine() + "\n"
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	_, wErr := io.WriteString(lw.w, line)
+	return wErr
+}
+
+// Handler writes the log line of the error, ignoring write
+// failures. It can be used as an ErrorCallbackHandler.
+func (lw *LogWriter) Handler(err *Error) {
+	_ = lw.Report(context.Background(), err)
+}
@@ -0,0 +1,33 @@
+package errors_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/oarkflow/errors"
+)
+
+func deepError(depth int) *errors.Error {
+	if depth == 0 {
+		return errors.NewInternal(nil, "failed", "deep", true)
+	}
+	return deepError(depth - 1)
+}
+
+func TestLogLineFullStack(t *testing.T) {
+	e := deepError(5)
+	var line struct {
+		Stack string `json:"stack"`
+	}
+	if err := json.Unmarshal([]byte(e.LogLine()), &line); err != nil {
+		t.Fatal(err)
+	}
+	frames := strings.Split(line.Stack, "\n")
+	if want := len(e.StackFrames()); len(frames) != want {
+		t.Errorf("stack has %d frames, want %d: %s", len(frames), want, line.Stack)
+	}
+	if n := strings.Count(line.Stack, "deepError"); n != 6 {
+		t.Errorf("stack holds %d deepError frames, want 6: %s", n, line.Stack)
+	}
+}
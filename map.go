@@ -0,0 +1,79 @@
+package errors
+
+import (
+	"encoding/json"
+)
+
+// mapCauseKey is the key of the map returned by ToMap holding the
+// wrapped Error.
+const mapCauseKey = "cause"
+
+// ToMap returns the fields of the JSON representation of the
+// error, shaped by DefaultMarshalOptions, as a map. A wrapped
+// Error is included under "cause" as a map of its own, so that
+// FromMap restores the whole chain. A nil error returns nil.
+func (e *Error) ToMap() (map[string]any, error) {
+	return e.toMap(0)
+}
+
+func (e *Error) toMap(depth int) (map[string]any, error) {
+	if e == nil {
+		return nil, nil
+	}
+	b, err := e.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	if err = json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	delete(m, mapCauseKey)
+	if inner, ok := e.Err.(*Error); ok && inner != nil && depth+1 < maxTreeDepth {
+		cause, err := inner.toMap(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		m[mapCauseKey] = cause
+	}
+	return m, nil
+}
+
+// FromMap returns the Error described by m, as returned by ToMap,
+// with the Errors found under "cause" restored as its chain. A nil
+// map returns nil.
+func FromMap(m map[string]any) (*Error, error) {
+	return fromMap(m, 0)
+}
+
+func fromMap(m map[string]any, depth int) (*Error, error) {
+	if m == nil {
+		return nil, nil
+	}
+	fields := make(map[string]any, len(m))
+	for k, v := range m {
+		fields[k] = v
+	}
+	causeMap, _ := fields[mapCauseKey].(map[string]any)
+	delete(fields, mapCauseKey)
+	normalized, err := jsonCompatible(fields)
+	if err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(normalized)
+	if err != nil {
+		return nil, err
+	}
+	e := &Error{}
+	if err = json.Unmarshal(b, e); err != nil {
+		return nil, err
+	}
+	if causeMap != nil && depth+1 < maxTreeDepth {
+		cause, err := fromMap(causeMap, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		e.Err = cause
+	}
+	return e, nil
+}
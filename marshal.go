@@ -0,0 +1,340 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// MarshalOptions shapes the JSON representation of an Error.
+// The zero value produces the historic wire format.
+type MarshalOptions struct {
+	// FieldNames renames fields, keyed by their default snake
+	// case name, e.g. {"additional": "stack"}.
+	FieldNames map[string]string
+	// CamelCase converts the default field names to camel case,
+	// e.g. "file_line" to "fileLine". Explicit FieldNames win.
+	CamelCase bool
+	// OmitEmpty leaves out every field holding a zero value.
+	OmitEmpty bool
+	// OmitStack leaves out the stack frames.
+	OmitStack bool
+	// Chain adds a "chain" array holding the code, operation,
+	// message, location and full stack frames of every Error
+	// wrapped by the encoded one, outermost first.
+	Chain bool
+	// Deterministic leaves out everything depending on where and
+	// when the error was created or seen: the ID, the file lines,
+	// the caller, the stacks and the occurrences. The output then
+	// only depends on the error content, e.g. for golden files.
+	Deterministic bool
+	// MaxMessageLength, MaxErrorLength and MaxMetadataLength limit
+	// the number of bytes written for the message, the wrapped
+	// error and every string metadata value. Longer values are cut
+	// and marked with "...", and their original lengths are written
+	// in a "truncated" object keyed by field name, or "metadata."
+	// followed by the key. Zero means no limit.
+	MaxMessageLength  int
+	MaxErrorLength    int
+	MaxMetadataLength int
+	// MergeMetadata writes the metadata of the whole chain, merged
+	// by AllMeta, instead of the metadata of the error alone.
+	MergeMetadata bool
+}
+
+// WireFormatVersion is the version of the JSON representation,
+// emitted in the "v" field.
+const WireFormatVersion = 1
+
+// DefaultMarshalOptions is used by MarshalJSON and UnmarshalJSON.
+var DefaultMarshalOptions MarshalOptions
+
+// Version returns the wire format version the error was decoded
+// from, or WireFormatVersion for errors created in process.
+func (e *Error) Version() int {
+	if e == nil || e.version == 0 {
+		return WireFormatVersion
+	}
+	return e.version
+}
+
+// Extra returns the fields not known to this version of the
+// package found when decoding the error. They are written back
+// as is by MarshalJSON, so errors relayed between services
+// running different versions keep all of their data.
+func (e *Error) Extra() map[string]json.RawMessage {
+	m := e.extraFields()
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]json.RawMessage, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+func (e *Error) extraFields() map[string]json.RawMessage {
+	if e == nil {
+		return nil
+	}
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.extra
+}
+
+// name returns the output name of the field.
+func (o MarshalOptions) name(field string) string {
+	if n, ok := o.FieldNames[field]; ok && n != "" {
+		return n
+	}
+	if o.CamelCase {
+		return camelCase(field)
+	}
+	return field
+}
+
+// splitFields renames the keys of the encoded object back to
+// their default names, so UnmarshalJSON reads what the options
+// produced, and returns the unknown fields separately.
+func (o MarshalOptions) splitFields(data []byte) ([]byte, map[string]json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, nil, err
+	}
+	if fields == nil {
+		return data, nil, nil
+	}
+	known := make(map[string]json.RawMessage, len(fields))
+	for _, f := range jsonFieldNames() {
+		name := o.name(f)
+		if v, ok := fields[name]; ok {
+			known[f] = v
+			delete(fields, name)
+		}
+	}
+	if len(fields) == 0 {
+		fields = nil
+	}
+	out, err := json.Marshal(known)
+	return out, fields, err
+}
+
+// jsonFieldNames returns the default names of the encoded fields.
+func jsonFieldNames() []string {
+	return []string{
+		"v", "id", "code", "top_code", "message", "operation", "error", "file_line",
+		"caller", "internal", "severity", "metadata", "violations", "retryable",
+		"occurrences", "first_seen", "last_seen", "panic_value", "notes",
+		StackFieldName,
+	}
+}
+
+// jsonField is a single field of an encoded Error.
+type jsonField struct {
+	name      string
+	value     any
+	empty     bool
+	omitEmpty bool
+}
+
+// MarshalJSONWithOptions returns the JSON encoding of the error
+// shaped by opts.
+func (e *Error) MarshalJSONWithOptions(opts MarshalOptions) ([]byte, error) {
+	if e == nil {
+		return []byte("null"), nil
+	}
+	var errMsg, fileLine string
+	caller := e.caller
+	if inner, ok := e.Err.(*Error); ok && inner != nil && opts.Deterministic {
+		errMsg = inner.errorString(0, false)
+	} else if e.Err != nil {
+		errMsg = e.Err.Error()
+		fileLine = e.fileLine
+	}
+	if opts.Deterministic {
+		fileLine = ""
+		caller = Caller{}
+		opts.OmitStack = true
+	}
+	topCode := TopLevelCode(e.Code)
+	if topCode == e.Code {
+		topCode = ""
+	}
+	metadata := e.metadata()
+	if opts.MergeMetadata {
+		metadata = AllMeta(e)
+	}
+	e.mu.RLock()
+	id := e.id
+	e.mu.RUnlock()
+	var truncated map[string]int
+	message := limitString(&truncated, "message", e.Message, opts.MaxMessageLength)
+	errMsg = limitString(&truncated, "error", errMsg, opts.MaxErrorLength)
+	if opts.MaxMetadataLength > 0 {
+		for k, v := range metadata {
+			if s, ok := v.(string); ok {
+				metadata[k] = limitString(&truncated, "metadata."+k, s, opts.MaxMetadataLength)
+			}
+		}
+	}
+	occurrences, firstSeen, lastSeen := e.occurrenceFields()
+	notes := e.Notes()
+	if opts.Deterministic {
+		id = ""
+		occurrences, firstSeen, lastSeen = 0, nil, nil
+		for i := range notes {
+			notes[i].FileLine = ""
+		}
+	}
+	fields := []jsonField{
+		{"v", WireFormatVersion, false, false},
+		{"id", id, id == "", true},
+		{"code", e.Code, e.Code == "", false},
+		{"top_code", topCode, topCode == "", true},
+		{"message", message, message == "", false},
+		{"operation", e.Operation, e.Operation == "", false},
+		{"error", errMsg, errMsg == "", false},
+		{"file_line", fileLine, fileLine == "", false},
+		{"caller", caller, caller.IsZero(), true},
+		{"internal", e.Internal, !e.Internal, false},
+		{"severity", e.Severity, e.Severity == 0, true},
+		{"metadata", metadata, len(metadata) == 0, true},
+		{"violations", e.Violations, len(e.Violations) == 0, true},
+		{"retryable", e.Retryable, !e.Retryable, true},
+		{"occurrences", occurrences, occurrences == 0, true},
+		{"first_seen", firstSeen, firstSeen == nil, true},
+		{"last_seen", lastSeen, lastSeen == nil, true},
+		{"panic_value", panicValueJSON(e.PanicValue), e.PanicValue == nil, true},
+		{"notes", notes, len(notes) == 0, true},
+	}
+	if len(truncated) > 0 {
+		fields = append(fields, jsonField{"truncated", truncated, false, false})
+	}
+	if !opts.OmitStack && e.HasStack() {
+		stack, err := marshalStack(e.Additional)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, jsonField{StackFieldName, json.RawMessage(stack), false, false})
+	}
+	if opts.Chain {
+		chain, err := e.marshalChain(opts)
+		if err != nil {
+			return nil, err
+		}
+		if chain != nil {
+			fields = append(fields, jsonField{"chain", json.RawMessage(chain), false, false})
+		}
+	}
+	return encodeFields(fields, e.extraFields(), opts)
+}
+
+// marshalChain encodes the Errors wrapped by e, or returns nil if
+// there are none.
+func (e *Error) marshalChain(opts MarshalOptions) ([]byte, error) {
+	var links []json.RawMessage
+	for _, w := range e.Chain()[1:] {
+		frames := w.StackFrames()
+		fileLine := w.fileLine
+		if opts.Deterministic {
+			fileLine = ""
+		}
+		fields := []jsonField{
+			{"code", w.Code, w.Code == "", false},
+			{"operation", w.Operation, w.Operation == "", true},
+			{"message", truncateString(w.Message, opts.MaxMessageLength), w.Message == "", true},
+			{"file_line", fileLine, fileLine == "", true},
+		}
+		if !opts.OmitStack && len(frames) > 0 {
+			stack, err := marshalStack(frames)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, jsonField{StackFieldName, json.RawMessage(stack), false, false})
+		}
+		link, err := encodeFields(fields, nil, opts)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+	if len(links) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(links)
+}
+
+// limitString returns s cut to max bytes, recording its original
+// length in truncated under name if it was cut.
+func limitString(truncated *map[string]int, name, s string, max int) string {
+	if max <= 0 || len(s) <= max {
+		return s
+	}
+	if *truncated == nil {
+		*truncated = make(map[string]int)
+	}
+	(*truncated)[name] = len(s)
+	return truncateString(s, max)
+}
+
+func encodeFields(fields []jsonField, extra map[string]json.RawMessage, opts MarshalOptions) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	first := true
+	written := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		if f.empty && (f.omitEmpty || opts.OmitEmpty) {
+			continue
+		}
+		written[opts.name(f.name)] = true
+		key, err := json.Marshal(opts.name(f.name))
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	keys := make([]string, 0, len(extra))
+	for k := range extra {
+		if !written[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(extra[k])
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func camelCase(s string) string {
+	parts := strings.Split(s, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
@@ -0,0 +1,55 @@
+package errors
+
+// MergeStrategy tells how AllMeta resolves the metadata keys found
+// in several Errors of a chain.
+type MergeStrategy int
+
+const (
+	// MergeWrapperWins keeps the value of the outermost Error.
+	MergeWrapperWins MergeStrategy = iota
+	// MergeCauseWins keeps the value of the innermost Error.
+	MergeCauseWins
+	// MergeNamespaced keeps the keys of the outermost Error as is
+	// and prefixes the keys of the wrapped Errors with their
+	// operation, or their code if they have none, and a dot, e.g.
+	// "repo.Insert.table". Remaining collisions keep the outermost
+	// value.
+	MergeNamespaced
+)
+
+// DefaultMergeStrategy is the strategy used by AllMeta.
+var DefaultMergeStrategy = MergeWrapperWins
+
+// AllMeta returns the metadata of every Error in the chain of err,
+// merged with the DefaultMergeStrategy. It returns nil if there is
+// none.
+func AllMeta(err error) map[string]any {
+	return AllMetaWith(err, DefaultMergeStrategy)
+}
+
+// AllMetaWith is like AllMeta with the given strategy.
+func AllMetaWith(err error, strategy MergeStrategy) map[string]any {
+	var merged map[string]any
+	for depth, e := range chainOf(err) {
+		prefix := ""
+		if strategy == MergeNamespaced && depth > 0 {
+			prefix = e.Operation
+			if prefix == "" {
+				prefix = e.Code
+			}
+			if prefix != "" {
+				prefix += "."
+			}
+		}
+		for k, v := range e.metadata() {
+			if merged == nil {
+				merged = make(map[string]any)
+			}
+			if _, exists := merged[prefix+k]; exists && strategy != MergeCauseWins {
+				continue
+			}
+			merged[prefix+k] = v
+		}
+	}
+	return merged
+}
@@ -0,0 +1,104 @@
+package errors
+
+import (
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// MetricCreated is the counter incremented for every created
+// error by MetricsHandler.
+const MetricCreated = "errors.created"
+
+// MetricsEmitter defines a sink for error counters.
+type MetricsEmitter interface {
+	Increment(name string, tags map[string]string)
+}
+
+// StatsD is a MetricsEmitter sending DogStatsD formatted
+// counters over UDP.
+type StatsD struct {
+	Prefix string
+	conn   net.Conn
+	mu     sync.Mutex
+}
+
+// NewStatsD returns a StatsD emitter writing to addr. The
+// prefix, if any, is prepended to every metric name.
+func NewStatsD(addr, prefix string) (*StatsD, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &StatsD{Prefix: prefix, conn: conn}, nil
+}
+
+// Increment sends a counter increment of one for the given
+// metric name and tags.
+func (s *StatsD) Increment(name string, tags map[string]string) {
+	if s == nil || s.conn == nil {
+		return
+	}
+	var buf strings.Builder
+	if s.Prefix != "" {
+		buf.WriteString(strings.TrimSuffix(s.Prefix, ".") + ".")
+	}
+	buf.WriteString(name)
+	buf.WriteString(":1|c")
+	if len(tags) > 0 {
+		keys := make([]string, 0, len(tags))
+		for k := range tags {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		buf.WriteString("|#")
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			buf.WriteString(k + ":" + tags[k])
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, _ = s.conn.Write([]byte(buf.String()))
+}
+
+// Close closes the underlying connection.
+func (s *StatsD) Close() error {
+	if s == nil || s.conn == nil {
+		return nil
+	}
+	return s.conn.Close()
+}
+
+// MetricsHandler returns an ErrorCallbackHandler incrementing
+// MetricCreated tagged with the code, operation and internal
+// flag of every created error.
+func MetricsHandler(emitter MetricsEmitter) ErrorCallbackHandler {
+	return func(err *Error) {
+		if emitter == nil || err == nil {
+			return
+		}
+		emitter.Increment(MetricCreated, map[string]string{
+			"code":     err.Code,
+			"op":       err.Operation,
+			"internal": strconv.FormatBool(err.Internal),
+		})
+	}
+}
+
+// ChainHandlers returns an ErrorCallbackHandler calling each
+// of the handlers in order, so several hooks can be set on
+// DefaultErrorCallbackHandler.
+func ChainHandlers(handlers ...ErrorCallbackHandler) ErrorCallbackHandler {
+	return func(err *Error) {
+		for _, h := range handlers {
+			if h != nil {
+				h(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package errors
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Multi is an error collecting several errors, such as the
+// failures of a batch operation.
+type Multi struct {
+	Errors []error `json:"errors"`
+}
+
+// Append adds the non-nil errors to the collection.
+func (m *Multi) Append(errs ...error) {
+	for _, err := range errs {
+		if err != nil {
+			m.Errors = append(m.Errors, err)
+		}
+	}
+}
+
+// Len returns the number of collected errors.
+func (m *Multi) Len() int {
+	if m == nil {
+		return 0
+	}
+	return len(m.Errors)
+}
+
+// ErrorOrNil returns the Multi as an error if it holds at least
+// one error, otherwise a true nil.
+func (m *Multi) ErrorOrNil() error {
+	if m.Len() == 0 {
+		return nil
+	}
+	return m
+}
+
+// Error implements the error interface.
+func (m *Multi) Error() string {
+	switch m.Len() {
+	case 0:
+		return ""
+	case 1:
+		return m.Errors[0].Error()
+	}
+	msgs := make([]string, 0, len(m.Errors))
+	for _, err := range m.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	return strconv.Itoa(len(m.Errors)) + " errors occurred: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the collected errors so Is and As look into
+// each of them.
+func (m *Multi) Unwrap() []error {
+	if m == nil {
+		return nil
+	}
+	return m.Errors
+}
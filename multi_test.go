@@ -0,0 +1,20 @@
+package errors_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/oarkflow/errors"
+)
+
+func TestMultiIsAs(t *testing.T) {
+	var m errors.Multi
+	m.Append(io.EOF, errors.NewNotFound(nil, "no user", "users.Get", false))
+	if !errors.Is(&m, io.EOF) {
+		t.Error("Is does not look into the collected errors")
+	}
+	var e *errors.Error
+	if !errors.As(&m, &e) || e.Code != errors.NOTFOUND {
+		t.Errorf("As = %v, want the collected NOTFOUND error", e)
+	}
+}
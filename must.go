@@ -0,0 +1,18 @@
+package errors
+
+// Must panics with an INTERNAL Error wrapping err, capturing
+// the stack of the caller, if err is not nil.
+func Must(err error) {
+	if err != nil {
+		panic(newError(err, "", INTERNAL, ""))
+	}
+}
+
+// MustV returns v if err is nil. Otherwise, it panics with an
+// INTERNAL Error wrapping err, capturing the stack of the caller.
+func MustV[T any](v T, err error) T {
+	if err != nil {
+		panic(newError(err, "", INTERNAL, ""))
+	}
+	return v
+}
@@ -0,0 +1,87 @@
+package errors
+
+// clone returns a copy of the error owning its own metadata and
+// violations, keeping the original stack.
+func (e *Error) clone() *Error {
+	c := &Error{
+		Code:          e.Code,
+		Message:       e.Message,
+		Operation:     e.Operation,
+		Err:           e.Err,
+		Additional:    e.Additional,
+		Internal:      e.Internal,
+		Severity:      e.Severity,
+		Metadata:      e.metadata(),
+		Retryable:     e.Retryable,
+		NotifyHandler: e.NotifyHandler,
+		PanicValue:    e.PanicValue,
+		Context:       e.Context,
+		fileLine:      e.fileLine,
+		caller:        e.caller,
+		pcs:           e.pcs,
+		version:       e.version,
+		extra:         e.extraFields(),
+	}
+	e.mu.RLock()
+	c.id = e.id
+	c.occurrences, c.firstSeen, c.lastSeen = e.occurrences, e.firstSeen, e.lastSeen
+	if e.notes != nil {
+		c.notes = append([]Note(nil), e.notes...)
+	}
+	e.mu.RUnlock()
+	if e.Violations != nil {
+		c.Violations = append([]FieldViolation(nil), e.Violations...)
+	}
+	return c
+}
+
+// metadata returns a copy of the metadata safe to read while
+// other goroutines call WithField.
+func (e *Error) metadata() map[string]any {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.Metadata == nil {
+		return nil
+	}
+	m := make(map[string]any, len(e.Metadata))
+	for k, v := range e.Metadata {
+		m[k] = v
+	}
+	return m
+}
+
+// WithOp returns a copy of the error with the operation set to
+// op. The receiver is left unchanged.
+func (e *Error) WithOp(op string) *Error {
+	if e == nil {
+		return nil
+	}
+	c := e.clone()
+	c.Operation = op
+	recordOp(op)
+	return c
+}
+
+// WithCode returns a copy of the error with the code set to
+// code. The Internal flag is set for INTERNAL codes and cleared
+// otherwise. The receiver is left unchanged.
+func (e *Error) WithCode(code string) *Error {
+	if e == nil {
+		return nil
+	}
+	c := e.clone()
+	c.Code = code
+	c.Internal = code == INTERNAL
+	return c
+}
+
+// WithMessage returns a copy of the error with the message set
+// to message. The receiver is left unchanged.
+func (e *Error) WithMessage(message string) *Error {
+	if e == nil {
+		return nil
+	}
+	c := e.clone()
+	c.Message = message
+	return c
+}
@@ -0,0 +1,10 @@
+module github.com/oarkflow/errors/muxerrors
+
+go 1.20
+
+require (
+	github.com/gorilla/mux v1.8.0
+	github.com/oarkflow/errors v0.0.0
+)
+
+replace github.com/oarkflow/errors => ../
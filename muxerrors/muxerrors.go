@@ -0,0 +1,39 @@
+// Package muxerrors names the operation of the errors produced
+// by gorilla/mux handlers after the matched route.
+package muxerrors
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/oarkflow/errors"
+)
+
+// Middleware sets the default operation of the request context to
+// the method and path template of the route matched by mux, such
+// as "GET /users/{id}". Register it with Router.Use so that it
+// runs once the route is matched.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		op := Op(r)
+		if op == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(errors.ContextWithOp(r.Context(), op)))
+	})
+}
+
+// Op returns the operation of a request routed by mux. It is
+// empty if no route matched.
+func Op(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return ""
+	}
+	tpl, err := route.GetPathTemplate()
+	if err != nil {
+		return ""
+	}
+	return r.Method + " " + tpl
+}
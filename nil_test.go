@@ -0,0 +1,45 @@
+package errors_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/oarkflow/errors"
+)
+
+func TestWrapNil(t *testing.T) {
+	const op errors.Op = "file.Read"
+	wrapped := map[string]error{
+		"Wrap":     errors.Wrap(nil, "read", "file.Read"),
+		"Op.Wrap":  op.Wrap(nil, "read"),
+		"WrapAuto": errors.WrapAuto(nil, "read"),
+		"WrapCtx":  errors.WrapCtx(context.Background(), nil, "read", "file.Read"),
+		"WrapArgs": errors.WrapArgs(nil, "file.Read", 1),
+	}
+	for name, err := range wrapped {
+		if err != nil {
+			t.Errorf("%s(nil) = %#v, want a nil error", name, err)
+		}
+	}
+}
+
+func TestChainWalkersTypedNil(t *testing.T) {
+	var typedNil *errors.Error
+	inputs := map[string]error{
+		"typed nil":         typedNil,
+		"wrapped typed nil": fmt.Errorf("w: %w", typedNil),
+	}
+	for name, err := range inputs {
+		for walker, f := range chainWalkers {
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						t.Errorf("%s(%s) panicked: %v", walker, name, r)
+					}
+				}()
+				f(err)
+			}()
+		}
+	}
+}
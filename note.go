@@ -0,0 +1,59 @@
+package errors
+
+import (
+	"strconv"
+
+	"github.com/oarkflow/errors/internal/hooks"
+)
+
+// Note is a remark attached to an error by WithNote, with the
+// location which added it.
+type Note struct {
+	Text     string `json:"text"`
+	FileLine string `json:"file_line,omitempty"`
+}
+
+// WithNote attaches note at the location of the caller and returns
+// the error for chaining, to tell what the code was doing when the
+// error went through it, e.g. "retrying with fallback region". The
+// notes are kept in the order they were added and are rendered by
+// ErrorWithStackTrace after the stack of their Error.
+func (e *Error) WithNote(note string) *Error {
+	if e == nil {
+		return nil
+	}
+	n := Note{Text: note}
+	if _, file, line, ok := hooks.Caller(1); ok {
+		n.FileLine = file + ":" + strconv.Itoa(line)
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.notes = append(e.notes, n)
+	return e
+}
+
+// Notes returns a copy of the notes attached to the error, in the
+// order they were added.
+func (e *Error) Notes() []Note {
+	if e == nil {
+		return nil
+	}
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if len(e.notes) == 0 {
+		return nil
+	}
+	return append([]Note(nil), e.notes...)
+}
+
+// Breadcrumbs returns the notes of the Errors of the chain of err
+// in the order they were likely added: those of the innermost Error
+// first.
+func Breadcrumbs(err error) []Note {
+	chain := chainOf(err)
+	var notes []Note
+	for i := len(chain) - 1; i >= 0; i-- {
+		notes = append(notes, chain[i].Notes()...)
+	}
+	return notes
+}
@@ -0,0 +1,80 @@
+package errors
+
+import (
+	"time"
+)
+
+// Touch records one more occurrence of the error, for long-lived
+// errors returned repeatedly, such as the error of a failing
+// dependency. It updates the occurrence count and the first and
+// last seen times, records the occurrence in DefaultStats, and
+// returns the error for chaining.
+func (e *Error) Touch() *Error {
+	if e == nil {
+		return nil
+	}
+	now := currentTime()
+	e.mu.Lock()
+	if e.occurrences == 0 {
+		e.firstSeen = now
+	}
+	e.occurrences++
+	e.lastSeen = now
+	e.mu.Unlock()
+	DefaultStats.recordError(e, now)
+	return e
+}
+
+// Occurrences returns the number of times Touch was called.
+func (e *Error) Occurrences() int64 {
+	if e == nil {
+		return 0
+	}
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.occurrences
+}
+
+// FirstSeen returns the time of the first call to Touch, or the
+// zero time if it was never called.
+func (e *Error) FirstSeen() time.Time {
+	if e == nil {
+		return time.Time{}
+	}
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.firstSeen
+}
+
+// LastSeen returns the time of the last call to Touch, or the zero
+// time if it was never called.
+func (e *Error) LastSeen() time.Time {
+	if e == nil {
+		return time.Time{}
+	}
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.lastSeen
+}
+
+// Age returns how long the error has been occurring, from its first
+// occurrence to now, or 0 if Touch was never called.
+func (e *Error) Age() time.Duration {
+	first := e.FirstSeen()
+	if first.IsZero() {
+		return 0
+	}
+	return currentTime().Sub(first)
+}
+
+// occurrenceFields returns the occurrence count and seen times,
+// with the times nil when unset so they are left out of JSON.
+func (e *Error) occurrenceFields() (int64, *time.Time, *time.Time) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.occurrences == 0 {
+		return 0, nil, nil
+	}
+	first, last := e.firstSeen, e.lastSeen
+	return e.occurrences, &first, &last
+}
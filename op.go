@@ -0,0 +1,190 @@
+package errors
+
+import (
+	"context"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Op is the name of an operation, such as "user.Create".
+// Declaring operations as constants makes them easy to find:
+//
+//	const opCreateUser errors.Op = "user.Create"
+//
+//	return opCreateUser.New(err, "unable to create user", errors.CONFLICT)
+type Op string
+
+// String returns the operation name.
+func (o Op) String() string {
+	return string(o)
+}
+
+// New returns an Error with the given code for the operation.
+func (o Op) New(err error, message, code string, disableErrorHandler ...bool) *Error {
+	return newError(err, message, code, string(o), disableErrorHandler...)
+}
+
+// Wrap returns an Error with the DefaultCode annotating err for
+// the operation. If err is nil, Wrap returns a true nil error.
+func (o Op) Wrap(err error, message string) error {
+	if err == nil {
+		return nil
+	}
+	return newError(err, message, DefaultCode, string(o), true)
+}
+
+// OpTransformer derives the operation of WrapAuto from the fully
+// qualified name of the calling function. The default keeps the
+// package name and drops the module path and pointer receiver
+// markers, e.g. "user.Service.Create" for
+// "github.com/acme/app/user.(*Service).Create".
+var OpTransformer = func(funcName string) string {
+	if i := strings.LastIndex(funcName, "/"); i >= 0 {
+		funcName = funcName[i+1:]
+	}
+	return strings.NewReplacer("(*", "", ")", "").Replace(funcName)
+}
+
+// WrapAuto is like Wrap with the operation derived from the name
+// of the calling function by OpTransformer.
+func WrapAuto(err error, message string) error {
+	if err == nil {
+		return nil
+	}
+	op := OpTransformer(callerFuncName(1))
+	e := buildError(1, err, message, DefaultCode, op)
+	notify(e)
+	return e
+}
+
+// callerFuncName returns the name of the function skip frames
+// above the caller of callerFuncName, resolving inlined calls.
+func callerFuncName(skip int) string {
+	var pcs [1]uintptr
+	if runtime.Callers(skip+2, pcs[:]) == 0 {
+		return ""
+	}
+	frame, _ := runtime.CallersFrames(pcs[:]).Next()
+	return frame.Function
+}
+
+type opKey struct{}
+
+// ContextWithOp returns a copy of ctx carrying op as the default
+// operation of the errors created with it, such as by WithContext
+// or Builder.Context, and written by the Responder.
+func ContextWithOp(ctx context.Context, op string) context.Context {
+	return ContextWithOpFunc(ctx, func() string { return op })
+}
+
+// ContextWithOpFunc is like ContextWithOp, with the operation
+// computed when an error needs it. This suits routers which only
+// know the matched route once the request has been dispatched.
+func ContextWithOpFunc(ctx context.Context, f func() string) context.Context {
+	return context.WithValue(ctx, opKey{}, f)
+}
+
+// OpFromContext returns the operation carried by ctx, if any:
+// the operation path built by PushOp, or else the operation set
+// by ContextWithOp.
+func OpFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if path := OpPath(ctx); len(path) > 0 {
+		return strings.Join(path, OpPathSeparator)
+	}
+	if f, ok := ctx.Value(opKey{}).(func() string); ok {
+		return f()
+	}
+	return ""
+}
+
+// OpPathSeparator separates the operations of a path built with
+// PushOp, e.g. "api.CreateUser > svc.CreateUser > repo.Insert".
+var OpPathSeparator = " > "
+
+type opPathKey struct{}
+
+// PushOp returns a copy of ctx whose operation path ends with op.
+// Errors created with the context, such as by WithContext or
+// WrapCtx, get the path as their operation, followed by their own
+// operation if it is not the last of the path, so the breadcrumb
+// is kept even when intermediate layers do not annotate errors.
+func PushOp(ctx context.Context, op string) context.Context {
+	parent := OpPath(ctx)
+	path := make([]string, len(parent), len(parent)+1)
+	copy(path, parent)
+	return context.WithValue(ctx, opPathKey{}, append(path, op))
+}
+
+// OpPath returns the operations pushed on ctx with PushOp, the
+// outermost first.
+func OpPath(ctx context.Context) []string {
+	if ctx == nil {
+		return nil
+	}
+	path, _ := ctx.Value(opPathKey{}).([]string)
+	return path
+}
+
+// contextOp returns the operation of an error created for op
+// with ctx.
+func contextOp(ctx context.Context, op string) string {
+	path := OpPath(ctx)
+	if len(path) == 0 {
+		if op == "" {
+			return OpFromContext(ctx)
+		}
+		return op
+	}
+	joined := strings.Join(path, OpPathSeparator)
+	if op == "" || op == path[len(path)-1] {
+		return joined
+	}
+	return joined + OpPathSeparator + op
+}
+
+// MaxOps is the maximum number of operations remembered by Ops
+// and counted per operation by a StatsCollector, so that dynamic
+// operations, such as the "METHOD URL" of the HTTP client errors
+// or the paths built by PushOp, do not grow them without limit.
+// The operations seen once the limit is reached are left out.
+var MaxOps = 1024
+
+var (
+	seenOps  sync.Map
+	seenOpsN atomic.Int64
+)
+
+// recordOp remembers the operation for Ops.
+func recordOp(op string) {
+	if op == "" {
+		return
+	}
+	if _, ok := seenOps.Load(op); ok {
+		return
+	}
+	if seenOpsN.Add(1) > int64(MaxOps) {
+		seenOpsN.Add(-1)
+		return
+	}
+	if _, loaded := seenOps.LoadOrStore(op, struct{}{}); loaded {
+		seenOpsN.Add(-1)
+	}
+}
+
+// Ops returns the sorted names of the operations errors have
+// been created for since the program started, up to MaxOps.
+func Ops() []string {
+	var ops []string
+	seenOps.Range(func(key, _ any) bool {
+		ops = append(ops, key.(string))
+		return true
+	})
+	sort.Strings(ops)
+	return ops
+}
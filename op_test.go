@@ -0,0 +1,44 @@
+package errors_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/oarkflow/errors"
+)
+
+func setMaxOps(t *testing.T, n int) {
+	prev := errors.MaxOps
+	errors.MaxOps = n
+	t.Cleanup(func() { errors.MaxOps = prev })
+}
+
+func TestOpsBounded(t *testing.T) {
+	limit := len(errors.Ops()) + 2
+	setMaxOps(t, limit)
+	for i := 0; i < 10; i++ {
+		errors.NewInternal(nil, "failed", "GET /users/"+strconv.Itoa(i), true)
+	}
+	if got := len(errors.Ops()); got != limit {
+		t.Errorf("len(Ops()) = %d, want %d", got, limit)
+	}
+}
+
+func TestStatsOpsBounded(t *testing.T) {
+	setMaxOps(t, 2)
+	s := errors.NewStatsCollector()
+	for i := 0; i < 10; i++ {
+		s.Handler(errors.NewInternal(nil, "failed", "GET /users/"+strconv.Itoa(i), true))
+	}
+	s.Handler(errors.NewInternal(nil, "failed", "GET /users/0", true))
+	snap := s.Snapshot()
+	if len(snap.Ops) != 2 {
+		t.Errorf("got %d operations, want 2", len(snap.Ops))
+	}
+	if got := snap.Ops["GET /users/0"].Count; got != 2 {
+		t.Errorf("count of a counted operation = %d, want 2", got)
+	}
+	if snap.Total != 11 {
+		t.Errorf("total = %d, want 11", snap.Total)
+	}
+}
@@ -0,0 +1,89 @@
+package errors
+
+// OutboundPolicy shapes the errors leaving the service, so that
+// internal details are removed in one place instead of in every
+// handler. It is applied by the Responder and the RPC adapters
+// through Outbound.
+type OutboundPolicy struct {
+	// Codes maps internal codes to the public codes sent instead.
+	// Hierarchical codes fall back to the mapping of their parents.
+	Codes map[string]string
+	// DropMetadata lists the metadata keys removed from the errors.
+	DropMetadata []string
+	// UserMessage replaces the message with UserMessage, hiding the
+	// messages of internal errors.
+	UserMessage bool
+	// DropCause removes the wrapped error.
+	DropCause bool
+}
+
+// DefaultOutbound is the policy applied by Outbound. A nil policy
+// leaves the errors unchanged.
+var DefaultOutbound *OutboundPolicy
+
+// Outbound applies DefaultOutbound to e, see OutboundPolicy.Apply.
+func Outbound(e *Error) *Error {
+	return DefaultOutbound.Apply(e)
+}
+
+// Apply returns a copy of e shaped by the policy. The Errors
+// wrapped by e are copied and shaped as well, so that their
+// metadata, messages and codes do not leak through the cause or
+// the merged metadata, and lose their file lines, which would
+// reveal the source layout in the error string. The receiver and
+// e are left unchanged. A nil policy returns e as is.
+func (p *OutboundPolicy) Apply(e *Error) *Error {
+	if p == nil || e == nil {
+		return e
+	}
+	c := e.clone()
+	c.Code = p.publicCode(Code(e))
+	c.Message = Message(e)
+	if p.UserMessage {
+		c.Message = UserMessage(e)
+	}
+	p.dropMetadata(c)
+	if p.DropCause {
+		c.Err = nil
+		return c
+	}
+	seen := map[*Error]bool{e: true}
+	for link := c; len(seen) < maxChainLength; {
+		inner, ok := link.Err.(*Error)
+		if !ok || inner == nil {
+			break
+		}
+		if seen[inner] {
+			link.Err = nil
+			break
+		}
+		seen[inner] = true
+		shaped := inner.clone()
+		if p.UserMessage && (inner.Internal || inner.Code == INTERNAL) {
+			shaped.Message = FallbackMessage(inner.Code)
+		}
+		if inner.Code != "" {
+			shaped.Code = p.publicCode(inner.Code)
+		}
+		p.dropMetadata(shaped)
+		shaped.fileLine, shaped.caller = "", Caller{}
+		link.Err = shaped
+		link = shaped
+	}
+	return c
+}
+
+func (p *OutboundPolicy) dropMetadata(c *Error) {
+	for _, key := range p.DropMetadata {
+		delete(c.Metadata, key)
+	}
+}
+
+func (p *OutboundPolicy) publicCode(code string) string {
+	for c := code; c != ""; c = ParentCode(c) {
+		if public, ok := p.Codes[c]; ok {
+			return public
+		}
+	}
+	return code
+}
@@ -0,0 +1,44 @@
+package errors_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/oarkflow/errors"
+)
+
+func TestOutboundShapesChain(t *testing.T) {
+	db := errors.NewInternal(errors.New("pq: relation users does not exist"), "select failed", "db.query", true).
+		WithField("query", "SELECT * FROM users")
+	e := errors.NewNotFound(db, "user not found", "users.get", true).
+		WithField("user", "42")
+	p := &errors.OutboundPolicy{
+		Codes:        map[string]string{errors.INTERNAL: "server_error"},
+		DropMetadata: []string{"query"},
+		UserMessage:  true,
+	}
+	out := p.Apply(e)
+
+	if _, ok := errors.AllMeta(out)["query"]; ok {
+		t.Errorf("merged metadata holds the dropped key: %v", errors.AllMeta(out))
+	}
+	if got := errors.AllMeta(out)["user"]; got != "42" {
+		t.Errorf("merged metadata lost user: %v", errors.AllMeta(out))
+	}
+	cause := out.Unwrap().Error()
+	for _, leak := range []string{"select failed", "outbound_test.go", "<internal>"} {
+		if strings.Contains(cause, leak) {
+			t.Errorf("cause %q holds %q", cause, leak)
+		}
+	}
+	if !strings.Contains(cause, "<server_error>") {
+		t.Errorf("cause %q lacks the public code", cause)
+	}
+
+	if _, ok := db.Meta("query"); !ok || db.Message != "select failed" {
+		t.Error("Apply changed the wrapped error")
+	}
+	if e.Unwrap() != db {
+		t.Error("Apply changed the cause of e")
+	}
+}
@@ -0,0 +1,136 @@
+package errors
+
+import (
+	"context"
+	"path"
+	"sync"
+)
+
+// ReportAction tells whether errors matched by a Rule are reported.
+type ReportAction int
+
+const (
+	// ReportDefault reports the error.
+	ReportDefault ReportAction = iota
+	// ReportNever marks matched errors as not reportable.
+	ReportNever
+	// ReportAlways marks matched errors as reportable.
+	ReportAlways
+)
+
+// Rule is a declarative mapping applied by a Policy to the errors
+// it matches. Empty criteria match every error.
+type Rule struct {
+	// Code matches the error code, or any of its descendants for
+	// hierarchical codes.
+	Code string
+	// Op is a path.Match pattern on the operation, e.g. "cache.*".
+	Op string
+	// Match, if set, must also return true for the rule to apply.
+	Match func(err *Error) bool
+
+	// Severity, if set, replaces the severity of the error.
+	Severity Severity
+	// Report tells whether the error is reported.
+	Report ReportAction
+	// Reporters, if set, replace the reporters the error is sent to.
+	Reporters []Reporter
+}
+
+func (r Rule) matches(e *Error) bool {
+	if r.Code != "" && !IsCodePrefix(e, r.Code) {
+		return false
+	}
+	if r.Op != "" {
+		if ok, _ := path.Match(r.Op, e.Operation); !ok {
+			return false
+		}
+	}
+	return r.Match == nil || r.Match(e)
+}
+
+// Decision is the outcome of applying a Policy to an error.
+type Decision struct {
+	Severity  Severity
+	Report    bool
+	Reporters []Reporter
+}
+
+// Policy holds ordered rules. The first matching rule wins.
+type Policy struct {
+	mu    sync.RWMutex
+	rules []Rule
+}
+
+// NewPolicy returns a Policy with the given rules.
+func NewPolicy(rules ...Rule) *Policy {
+	return &Policy{rules: rules}
+}
+
+// Add appends a rule to the policy.
+func (p *Policy) Add(r Rule) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.rules = append(p.rules, r)
+}
+
+// Apply returns the error with the severity of the first matching
+// rule, and the decision of that rule. The input error is never
+// modified; a copy is returned when the severity changes. A nil
+// error returns nil and a zero Decision.
+func (p *Policy) Apply(err error) (*Error, Decision) {
+	if IsNil(err) {
+		return nil, Decision{}
+	}
+	e := from(1, err)
+	d := Decision{Severity: SeverityOf(e), Report: true}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for _, r := range p.rules {
+		if !r.matches(e) {
+			continue
+		}
+		if r.Severity != 0 && r.Severity != e.Severity {
+			e = e.clone()
+			e.Severity = r.Severity
+			d.Severity = r.Severity
+		}
+		switch r.Report {
+		case ReportNever:
+			d.Report = false
+		case ReportAlways:
+			d.Report = true
+		}
+		if len(r.Reporters) > 0 {
+			d.Reporters = r.Reporters
+		}
+		break
+	}
+	return e, d
+}
+
+// Reporter returns a Reporter applying the policy before sending
+// reportable errors to the reporters of the decision, or to next
+// when the decision names none.
+func (p *Policy) Reporter(next Reporter) Reporter {
+	return ReporterFunc(func(ctx context.Context, err *Error) error {
+		e, d := p.Apply(err)
+		if e == nil || !d.Report {
+			return nil
+		}
+		reporters := d.Reporters
+		if len(reporters) == 0 {
+			if next == nil {
+				return nil
+			}
+			reporters = []Reporter{next}
+		}
+		var first error
+		for _, r := range reporters {
+			if rErr := r.Report(ctx, e); rErr != nil && first == nil {
+				first = rErr
+			}
+		}
+		return first
+	})
+}
@@ -0,0 +1,34 @@
+package errors
+
+import (
+	"strings"
+)
+
+// Pretty returns a human-readable, multi-line rendering of the
+// error chain suitable for terminals.
+func Pretty(err error) string {
+	if err == nil {
+		return ""
+	}
+	var buf strings.Builder
+	buf.WriteString("Error: " + Message(err) + "\n")
+	indent := "  "
+	root := err
+	for _, e := range chainOf(err) {
+		if e.Code != "" {
+			buf.WriteString(indent + "code: " + e.Code + "\n")
+		}
+		if e.Operation != "" {
+			buf.WriteString(indent + "operation: " + e.Operation + "\n")
+		}
+		if e.fileLine != "" {
+			buf.WriteString(indent + "at: " + e.fileLine + "\n")
+		}
+		root = e.Err
+		indent += "  "
+	}
+	if _, ok := root.(*Error); !ok && root != nil {
+		buf.WriteString(indent + "cause: " + root.Error() + "\n")
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
@@ -0,0 +1,51 @@
+package errors
+
+import (
+	"unicode/utf8"
+)
+
+const (
+	// MetaQuery is the metadata key of the SQL statement recorded
+	// by WithQuery.
+	MetaQuery = "query"
+	// MetaQueryArgs is the metadata key of the statement arguments
+	// recorded by WithQuery.
+	MetaQueryArgs = "query_args"
+)
+
+var (
+	// QueryMaxLength is the maximum number of bytes of the statement
+	// recorded by WithQuery.
+	QueryMaxLength = 4096
+)
+
+// WithQuery records the SQL statement and its arguments and returns
+// the error for chaining. Long statements are truncated, and the
+// arguments are recorded like by WrapArgs, see RawArgs.
+func (e *Error) WithQuery(query string, args ...any) *Error {
+	if e == nil {
+		return nil
+	}
+	e.WithField(MetaQuery, truncateString(query, QueryMaxLength))
+	if len(args) == 0 {
+		return e
+	}
+	recorded := make([]any, len(args))
+	for i, arg := range args {
+		recorded[i] = recordedArg(arg)
+	}
+	return e.WithField(MetaQueryArgs, recorded)
+}
+
+// truncateString cuts s to at most max bytes on a rune boundary,
+// marking the cut with "...".
+func truncateString(s string, max int) string {
+	if max <= 0 || len(s) <= max {
+		return s
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "..."
+}
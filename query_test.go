@@ -0,0 +1,45 @@
+package errors_test
+
+import (
+	"testing"
+
+	"github.com/oarkflow/errors"
+)
+
+func TestWithQueryRedactsByDefault(t *testing.T) {
+	e := errors.NewInternal(nil, "insert failed", "users.Insert", true).
+		WithQuery("INSERT INTO users (email, password) VALUES ($1, $2)", "a@b.c", "hunter2")
+	args, _ := e.Meta(errors.MetaQueryArgs)
+	got := args.([]any)
+	if len(got) != 2 || got[0] != errors.SecretMask || got[1] != errors.SecretMask {
+		t.Errorf("query args = %v, want masked", got)
+	}
+
+	errors.RawArgs = true
+	defer func() { errors.RawArgs = false }()
+	e = errors.NewInternal(nil, "insert failed", "users.Insert", true).
+		WithQuery("INSERT INTO users (email, password) VALUES ($1, $2)", "a@b.c", errors.NewSecret("hunter2"))
+	args, _ = e.Meta(errors.MetaQueryArgs)
+	got = args.([]any)
+	if got[0] != "a@b.c" || got[1] != errors.SecretMask {
+		t.Errorf("raw query args = %v", got)
+	}
+}
+
+func TestWrapArgsRedactsByDefault(t *testing.T) {
+	err := errors.WrapArgs(errors.New("failed"), "users.Login", "a@b.c", 42)
+	e := err.(*errors.Error)
+	if got, _ := e.Meta("arg0"); got != errors.SecretMask {
+		t.Errorf("arg0 = %v, want masked", got)
+	}
+
+	errors.RawArgs = true
+	defer func() { errors.RawArgs = false }()
+	e = errors.WrapArgs(errors.New("failed"), "users.Login", "a@b.c", errors.NewSecret("hunter2")).(*errors.Error)
+	if got, _ := e.Meta("arg0"); got != "a@b.c" {
+		t.Errorf("raw arg0 = %v", got)
+	}
+	if got, _ := e.Meta("arg1"); got != errors.SecretMask {
+		t.Errorf("secret arg1 = %v, want masked", got)
+	}
+}
@@ -0,0 +1,86 @@
+package errors
+
+import (
+	"strings"
+	"sync"
+)
+
+// CodeSeparator separates the segments of hierarchical codes
+// such as "db.conflict.duplicate_key".
+const CodeSeparator = "."
+
+// CodeInfo describes an application error code.
+type CodeInfo struct {
+	Code       string
+	HTTPStatus int
+	Message    string
+	DocURL     string
+	// ProblemType and ProblemTitle are the type URI and title of
+	// the problem details of the code, see ToProblem.
+	ProblemType  string
+	ProblemTitle string
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]CodeInfo)
+)
+
+// RegisterCode registers the code described by info, replacing
+// any previous registration.
+func RegisterCode(info CodeInfo) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[info.Code] = info
+}
+
+// LookupCode returns the registration of the code or, for
+// hierarchical codes, of its nearest registered parent.
+func LookupCode(code string) (CodeInfo, bool) {
+	for c := code; c != ""; c = ParentCode(c) {
+		if info, ok := lookupCode(c); ok {
+			return info, true
+		}
+	}
+	return CodeInfo{}, false
+}
+
+func lookupCode(code string) (CodeInfo, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	info, ok := registry[code]
+	return info, ok
+}
+
+// ParentCode returns the parent of a hierarchical code, e.g.
+// "db.conflict" for "db.conflict.duplicate_key", or "" for a
+// top-level code.
+func ParentCode(code string) string {
+	if i := strings.LastIndex(code, CodeSeparator); i >= 0 {
+		return code[:i]
+	}
+	return ""
+}
+
+// TopLevelCode returns the first segment of a hierarchical
+// code, e.g. "db" for "db.conflict.duplicate_key".
+func TopLevelCode(code string) string {
+	if i := strings.Index(code, CodeSeparator); i >= 0 {
+		return code[:i]
+	}
+	return code
+}
+
+// IsCodePrefix reports whether the code of err equals prefix or
+// is one of its descendants, e.g. "db.conflict.duplicate_key"
+// matches the prefix "db.conflict" but not "db.conf".
+func IsCodePrefix(err error, prefix string) bool {
+	if err == nil {
+		return false
+	}
+	return hasCodePrefix(Code(err), prefix)
+}
+
+func hasCodePrefix(code, prefix string) bool {
+	return code == prefix || strings.HasPrefix(code, prefix+CodeSeparator)
+}
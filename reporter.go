@@ -0,0 +1,289 @@
+package errors
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrQueueFull is returned by Dispatcher.Report when the
+	// queue has no capacity left.
+	ErrQueueFull = New("errors: reporter queue is full")
+	// ErrDispatcherClosed is returned by Dispatcher.Report
+	// after Close has been called.
+	ErrDispatcherClosed = New("errors: reporter dispatcher is closed")
+)
+
+// Reporter defines a destination for application errors, such
+// as an error tracker or a notification channel.
+type Reporter interface {
+	Report(ctx context.Context, err *Error) error
+}
+
+// BatchReporter is implemented by reporters able to send
+// several errors at once. The Dispatcher prefers ReportBatch
+// over Report when available.
+type BatchReporter interface {
+	Reporter
+	ReportBatch(ctx context.Context, errs []*Error) error
+}
+
+// ReporterFunc adapts an ordinary function to a Reporter.
+type ReporterFunc func(ctx context.Context, err *Error) error
+
+// Report calls f(ctx, err).
+func (f ReporterFunc) Report(ctx context.Context, err *Error) error {
+	return f(ctx, err)
+}
+
+// ReportHandler returns an ErrorCallbackHandler sending every
+// created error to the reporter.
+func ReportHandler(r Reporter) ErrorCallbackHandler {
+	return func(err *Error) {
+		if r == nil || err == nil {
+			return
+		}
+		ctx := err.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		_ = r.Report(ctx, err)
+	}
+}
+
+// DispatcherConfig configures a Dispatcher. Zero values are
+// replaced by sensible defaults.
+type DispatcherConfig struct {
+	// QueueSize is the maximum number of pending errors.
+	QueueSize int
+	// BatchSize is the maximum number of errors sent at once.
+	BatchSize int
+	// FlushInterval is the maximum time an error waits in the
+	// queue before being sent.
+	FlushInterval time.Duration
+	// MaxRetries is the number of retries per sink after a
+	// failed report.
+	MaxRetries int
+	// Backoff is the initial delay between retries, doubled
+	// after every attempt up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// OnDrop, if set, is called for every error a sink failed
+	// to accept after all retries.
+	OnDrop func(sink Reporter, err *Error, reportErr error)
+}
+
+func (c *DispatcherConfig) setDefaults() {
+	if c.QueueSize <= 0 {
+		c.QueueSize = 1024
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 50
+	}
+	if c.FlushInterval <= 0 {
+		c.FlushInterval = time.Second
+	}
+	if c.MaxRetries < 0 {
+		c.MaxRetries = 0
+	}
+	if c.Backoff <= 0 {
+		c.Backoff = 100 * time.Millisecond
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = 10 * time.Second
+	}
+}
+
+// Dispatcher is an asynchronous Reporter buffering errors in a
+// bounded queue and fanning them out in batches to its sinks.
+type Dispatcher struct {
+	config   DispatcherConfig
+	sinks    []Reporter
+	queue    chan *Error
+	flushReq chan chan struct{}
+	stop     chan struct{}
+	done     chan struct{}
+	ctx      context.Context
+	cancel   context.CancelFunc
+	mu       sync.RWMutex
+	closed   bool
+	once     sync.Once
+}
+
+// NewDispatcher returns a started Dispatcher sending errors to
+// every one of the sinks.
+func NewDispatcher(config DispatcherConfig, sinks ...Reporter) *Dispatcher {
+	config.setDefaults()
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &Dispatcher{
+		config:   config,
+		sinks:    sinks,
+		queue:    make(chan *Error, config.QueueSize),
+		flushReq: make(chan chan struct{}),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+	go d.run()
+	return d
+}
+
+// Report enqueues the error without blocking. It returns
+// ErrQueueFull when the queue is full and ErrDispatcherClosed
+// once the dispatcher has been closed.
+func (d *Dispatcher) Report(_ context.Context, err *Error) error {
+	if err == nil {
+		return nil
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return ErrDispatcherClosed
+	}
+	select {
+	case d.queue <- err:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Flush blocks until every error queued before the call has
+// been handed to the sinks, or ctx is done.
+func (d *Dispatcher) Flush(ctx context.Context) error {
+	req := make(chan struct{})
+	select {
+	case d.flushReq <- req:
+	case <-d.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-req:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops accepting errors, sends the pending ones and
+// waits for completion. If ctx is done first, in-flight
+// retries are abandoned and ctx.Err() is returned.
+func (d *Dispatcher) Close(ctx context.Context) error {
+	d.once.Do(func() {
+		d.mu.Lock()
+		d.closed = true
+		d.mu.Unlock()
+		close(d.stop)
+	})
+	select {
+	case <-d.done:
+		return nil
+	case <-ctx.Done():
+		d.cancel()
+		return ctx.Err()
+	}
+}
+
+func (d *Dispatcher) run() {
+	defer close(d.done)
+	defer d.cancel()
+	ticker := time.NewTicker(d.config.FlushInterval)
+	defer ticker.Stop()
+	batch := make([]*Error, 0, d.config.BatchSize)
+	send := func() {
+		if len(batch) > 0 {
+			d.dispatch(batch)
+			batch = make([]*Error, 0, d.config.BatchSize)
+		}
+	}
+	drain := func() {
+		for {
+			select {
+			case e := <-d.queue:
+				batch = append(batch, e)
+				if len(batch) >= d.config.BatchSize {
+					send()
+				}
+			default:
+				send()
+				return
+			}
+		}
+	}
+	for {
+		select {
+		case e := <-d.queue:
+			batch = append(batch, e)
+			if len(batch) >= d.config.BatchSize {
+				send()
+			}
+		case <-ticker.C:
+			send()
+		case req := <-d.flushReq:
+			drain()
+			close(req)
+		case <-d.stop:
+			drain()
+			return
+		}
+	}
+}
+
+func (d *Dispatcher) dispatch(batch []*Error) {
+	var wg sync.WaitGroup
+	for _, sink := range d.sinks {
+		wg.Add(1)
+		go func(sink Reporter) {
+			defer wg.Done()
+			d.send(sink, batch)
+		}(sink)
+	}
+	wg.Wait()
+}
+
+func (d *Dispatcher) send(sink Reporter, batch []*Error) {
+	if br, ok := sink.(BatchReporter); ok {
+		if err := d.retry(func() error { return br.ReportBatch(d.ctx, batch) }); err != nil {
+			d.drop(sink, batch, err)
+		}
+		return
+	}
+	for _, e := range batch {
+		e := e
+		if err := d.retry(func() error { return sink.Report(d.ctx, e) }); err != nil {
+			d.drop(sink, []*Error{e}, err)
+		}
+	}
+}
+
+func (d *Dispatcher) retry(fn func() error) error {
+	backoff := d.config.Backoff
+	err := fn()
+	for attempt := 0; err != nil && attempt < d.config.MaxRetries; attempt++ {
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-d.ctx.Done():
+			timer.Stop()
+			return err
+		}
+		if backoff *= 2; backoff > d.config.MaxBackoff {
+			backoff = d.config.MaxBackoff
+		}
+		err = fn()
+	}
+	return err
+}
+
+func (d *Dispatcher) drop(sink Reporter, batch []*Error, reportErr error) {
+	if d.config.OnDrop == nil {
+		return
+	}
+	for _, e := range batch {
+		d.config.OnDrop(sink, e, reportErr)
+	}
+}
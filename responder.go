@@ -0,0 +1,462 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"html/template"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Media types supported by the Responder.
+const (
+	MediaJSON    = "application/json"
+	MediaProblem = "application/problem+json"
+	MediaXML     = "application/xml"
+	MediaText    = "text/plain"
+	MediaHTML    = "text/html"
+)
+
+// Response headers emitted by the Responder.
+const (
+	HeaderErrorCode  = "X-Error-Code"
+	HeaderErrorID    = "X-Error-ID"
+	HeaderRequestID  = "X-Request-ID"
+	HeaderRetryAfter = "Retry-After"
+
+	HeaderBudgetLimit     = "X-Error-Budget-Limit"
+	HeaderBudgetRemaining = "X-Error-Budget-Remaining"
+)
+
+// MetaRequestID is the metadata key the Responder reads the
+// request ID from.
+const MetaRequestID = "request_id"
+
+// HeaderOptions selects the error fields the Responder emits as
+// response headers alongside the body.
+type HeaderOptions struct {
+	Code       bool
+	ErrorID    bool
+	RequestID  bool
+	RetryAfter bool
+	// Budget emits the error budget recorded by Budget.Annotate or
+	// Budget.Check.
+	Budget bool
+}
+
+// Renderer writes the error in a given media type.
+type Renderer func(w io.Writer, e *Error) error
+
+// Responder writes errors to HTTP responses, choosing the
+// representation from the Accept header of the request.
+type Responder struct {
+	// MarshalOptions shapes the JSON representation.
+	MarshalOptions MarshalOptions
+	// Debug sends the internal details of the errors: their
+	// messages, causes, file lines, callers, stacks, notes and all
+	// of their metadata, and renders the stack trace in the
+	// text/html page. It is meant for development only; otherwise
+	// internal errors get their UserMessage, and only the
+	// PublicMetadata is sent.
+	Debug bool
+	// PublicMetadata lists the metadata keys sent to the clients
+	// when Debug is not set.
+	PublicMetadata []string
+	// Headers selects the error fields sent as headers.
+	Headers HeaderOptions
+	// MaxBodySize, if positive, is the maximum size of the response
+	// body. Larger errors lose their stacks, then their metadata,
+	// then have their message and cause truncated, until they fit
+	// or nothing is left to cut.
+	MaxBodySize int
+
+	mu        sync.RWMutex
+	renderers map[string]Renderer
+	order     []string
+}
+
+// DefaultResponder is used by WriteError.
+var DefaultResponder = NewResponder()
+
+// NewResponder returns a Responder supporting JSON, problem+json,
+// XML, plain text and HTML, with JSON as the default.
+func NewResponder() *Responder {
+	rs := &Responder{}
+	rs.Register(MediaJSON, rs.renderJSON)
+	rs.Register(MediaProblem, func(w io.Writer, e *Error) error {
+		return json.NewEncoder(w).Encode(e.ToProblem())
+	})
+	rs.Register(MediaXML, func(w io.Writer, e *Error) error {
+		if _, err := io.WriteString(w, xml.Header); err != nil {
+			return err
+		}
+		return xml.NewEncoder(w).Encode(e.toXML())
+	})
+	rs.Register(MediaText, func(w io.Writer, e *Error) error {
+		_, err := io.WriteString(w, Message(e)+"\n")
+		return err
+	})
+	rs.Register(MediaHTML, func(w io.Writer, e *Error) error {
+		return rs.writeHTML(w, e)
+	})
+	return rs
+}
+
+// Register adds or replaces the renderer of a media type. Media
+// types registered first are preferred when the client accepts
+// several with the same quality.
+func (rs *Responder) Register(mediaType string, r Renderer) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if rs.renderers == nil {
+		rs.renderers = make(map[string]Renderer)
+	}
+	if _, ok := rs.renderers[mediaType]; !ok {
+		rs.order = append(rs.order, mediaType)
+	}
+	rs.renderers[mediaType] = r
+}
+
+// WriteError writes err to w using the DefaultResponder.
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	DefaultResponder.WriteError(w, r, err)
+}
+
+// WriteError writes err to w with the status code of the error,
+// in the representation preferred by the Accept header of r. An
+// error without operation takes the one carried by the request
+// context, see ContextWithOp. The error is shaped by Outbound
+// first.
+func (rs *Responder) WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	e := from(1, err)
+	if e == nil {
+		return
+	}
+	// The ID is assigned before e is copied, so that the response
+	// quotes the ID of the error seen by the hooks and the logs.
+	e.ID()
+	e = Outbound(e)
+	accept := ""
+	if r != nil {
+		accept = r.Header.Get("Accept")
+		if e.Operation == "" {
+			if op := OpFromContext(r.Context()); op != "" {
+				e = e.WithOp(op)
+			}
+		}
+	}
+	rs.writeHeaders(w, r, e)
+	if !rs.Debug {
+		e = rs.public(e)
+	}
+	mediaType, render := rs.negotiate(accept)
+	buf, rErr := rs.render(render, e)
+	if rErr != nil {
+		http.Error(w, Message(e), HTTPStatus(e))
+		return
+	}
+	if mediaType == MediaText || mediaType == MediaHTML {
+		mediaType += "; charset=utf-8"
+	}
+	w.Header().Set("Content-Type", mediaType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(HTTPStatus(e))
+	_, _ = w.Write(buf.Bytes())
+}
+
+// public returns a copy of e holding only what the clients may see:
+// the code, the user message, the violations and the
+// PublicMetadata, without cause, locations, stack, notes or panic
+// value.
+func (rs *Responder) public(e *Error) *Error {
+	c := e.clone()
+	c.Code = Code(e)
+	c.Message = UserMessage(e)
+	c.Violations = Violations(e)
+	c.Retryable = IsRetryable(e)
+	c.Err = nil
+	c.fileLine, c.caller = "", Caller{}
+	c.pcs, c.Additional = nil, nil
+	c.notes, c.extra = nil, nil
+	c.PanicValue = nil
+	c.Metadata = nil
+	for _, key := range rs.PublicMetadata {
+		if v, ok := e.Meta(key); ok {
+			if c.Metadata == nil {
+				c.Metadata = make(map[string]any)
+			}
+			c.Metadata[key] = v
+		}
+	}
+	return c
+}
+
+// render renders e, reduced to fit in MaxBodySize if needed.
+func (rs *Responder) render(render Renderer, e *Error) (*bytes.Buffer, error) {
+	var buf bytes.Buffer
+	err := render(&buf, e)
+	if err != nil || rs.MaxBodySize <= 0 || buf.Len() <= rs.MaxBodySize {
+		return &buf, err
+	}
+	fits := func(c *Error) bool {
+		buf.Reset()
+		err = render(&buf, c)
+		return err != nil || buf.Len() <= rs.MaxBodySize
+	}
+	chain := withoutStacks(e)
+	if fits(chain[0]) {
+		return &buf, err
+	}
+	for _, c := range chain {
+		c.Metadata = nil
+	}
+	if fits(chain[0]) {
+		return &buf, err
+	}
+	c := chain[0]
+	message, cause := c.Message, ""
+	if c.Err != nil {
+		cause = c.Err.Error()
+	}
+	for n := (len(message) + len(cause)) / 2; n > 0; n /= 2 {
+		c.Message, c.Err = truncateString(message, n), nil
+		if cause != "" {
+			c.Err = New(truncateString(cause, n))
+		}
+		if fits(c) {
+			break
+		}
+	}
+	return &buf, err
+}
+
+// withoutStacks returns copies of the Errors of the chain of e,
+// linked together, without their stacks.
+func withoutStacks(e *Error) []*Error {
+	chain := e.Chain()
+	for i, c := range chain {
+		c = c.clone()
+		c.pcs, c.Additional = nil, nil
+		chain[i] = c
+		if i > 0 {
+			chain[i-1].Err = c
+		}
+	}
+	if last := chain[len(chain)-1]; last.Err != nil {
+		if _, ok := last.Err.(*Error); ok {
+			last.Err = nil
+		}
+	}
+	return chain
+}
+
+func (rs *Responder) writeHeaders(w http.ResponseWriter, r *http.Request, e *Error) {
+	h := w.Header()
+	if rs.Headers.Code {
+		h.Set(HeaderErrorCode, Code(e))
+	}
+	if rs.Headers.ErrorID {
+		h.Set(HeaderErrorID, e.ID())
+	}
+	if rs.Headers.RequestID {
+		id, _ := e.Meta(MetaRequestID)
+		if s, ok := id.(string); ok && s != "" {
+			h.Set(HeaderRequestID, s)
+		} else if r != nil && r.Header.Get(HeaderRequestID) != "" {
+			h.Set(HeaderRequestID, r.Header.Get(HeaderRequestID))
+		}
+	}
+	if rs.Headers.RetryAfter {
+		if d, ok := RetryAfter(e); ok {
+			h.Set(HeaderRetryAfter, strconv.Itoa(int((d+time.Second-1)/time.Second)))
+		}
+	}
+	if rs.Headers.Budget {
+		if v := metaString(e, MetaBudgetLimit); v != "" {
+			h.Set(HeaderBudgetLimit, v)
+		}
+		if v := metaString(e, MetaBudgetRemaining); v != "" {
+			h.Set(HeaderBudgetRemaining, v)
+		}
+	}
+}
+
+type acceptRange struct {
+	mediaType string
+	q         float64
+}
+
+// negotiate returns the registered media type best matching the
+// Accept header, falling back to the first registered one.
+func (rs *Responder) negotiate(accept string) (string, Renderer) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+	var ranges []acceptRange
+	for _, part := range strings.Split(accept, ",") {
+		mt, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if f, pErr := strconv.ParseFloat(v, 64); pErr == nil {
+				q = f
+			}
+		}
+		if q > 0 {
+			ranges = append(ranges, acceptRange{mt, q})
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].q > ranges[j].q })
+	for _, ar := range ranges {
+		for _, mt := range rs.order {
+			if mediaMatches(ar.mediaType, mt) {
+				return mt, rs.renderers[mt]
+			}
+		}
+	}
+	if len(rs.order) == 0 {
+		// A zero Responder renders JSON.
+		return MediaJSON, rs.renderJSON
+	}
+	mt := rs.order[0]
+	return mt, rs.renderers[mt]
+}
+
+// renderJSON is the renderer of MediaJSON.
+func (rs *Responder) renderJSON(w io.Writer, e *Error) error {
+	b, err := e.MarshalJSONWithOptions(rs.MarshalOptions)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+func mediaMatches(pattern, mediaType string) bool {
+	if pattern == "*/*" || pattern == mediaType {
+		return true
+	}
+	if strings.HasSuffix(pattern, "/*") {
+		return strings.HasPrefix(mediaType, strings.TrimSuffix(pattern, "*"))
+	}
+	return false
+}
+
+// Problem is an RFC 7807 problem details object.
+type Problem struct {
+	Type       string           `json:"type"`
+	Title      string           `json:"title"`
+	Status     int              `json:"status"`
+	Detail     string           `json:"detail,omitempty"`
+	Instance   string           `json:"instance,omitempty"`
+	Code       string           `json:"code,omitempty"`
+	Operation  string           `json:"operation,omitempty"`
+	Violations []FieldViolation `json:"violations,omitempty"`
+}
+
+// ProblemTypeBase, if set, is the prefix of the problem type URI
+// of the codes registered without ProblemType, followed by the
+// code, e.g. "https://errors.example.com/" gives
+// "https://errors.example.com/conflict".
+var ProblemTypeBase string
+
+// ToProblem converts the error to an RFC 7807 problem details
+// object. The type and title are those registered for the code,
+// or its nearest registered parent, see RegisterCode. Otherwise,
+// the type is made from ProblemTypeBase, or "about:blank" if it is
+// unset, and the title is the HTTP status text.
+func (e *Error) ToProblem() Problem {
+	status := HTTPStatus(e)
+	code := Code(e)
+	problemType, title := "about:blank", http.StatusText(status)
+	if ProblemTypeBase != "" {
+		problemType = ProblemTypeBase + url.PathEscape(code)
+	}
+	if info, ok := LookupCode(code); ok {
+		if info.ProblemType != "" {
+			problemType = info.ProblemType
+		}
+		if info.ProblemTitle != "" {
+			title = info.ProblemTitle
+		}
+	}
+	return Problem{
+		Type:       problemType,
+		Title:      title,
+		Status:     status,
+		Detail:     Message(e),
+		Code:       code,
+		Operation:  e.Operation,
+		Violations: Violations(e),
+	}
+}
+
+// xmlError is the XML representation of an Error.
+type xmlError struct {
+	XMLName    xml.Name         `xml:"error"`
+	Code       string           `xml:"code"`
+	Message    string           `xml:"message"`
+	Operation  string           `xml:"operation,omitempty"`
+	Err        string           `xml:"cause,omitempty"`
+	Violations []FieldViolation `xml:"violations>violation,omitempty"`
+}
+
+func (e *Error) toXML() xmlError {
+	x := xmlError{
+		Code:       Code(e),
+		Message:    Message(e),
+		Operation:  e.Operation,
+		Violations: Violations(e),
+	}
+	if e.Err != nil {
+		x.Err = e.Err.Error()
+	}
+	return x
+}
+
+var htmlTemplate = template.Must(template.New("error").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Status}} {{.Title}}</title></head>
+<body>
+<h1>{{.Status}} {{.Title}}</h1>
+<p>{{.Message}}</p>
+{{- if .Code}}
+<p>Code: <code>{{.Code}}</code></p>
+{{- end}}
+{{- if .Debug}}
+<pre>{{.Trace}}</pre>
+{{- end}}
+</body>
+</html>
+`))
+
+func (rs *Responder) writeHTML(w io.Writer, e *Error) error {
+	status := HTTPStatus(e)
+	data := struct {
+		Status  int
+		Title   string
+		Message string
+		Code    string
+		Debug   bool
+		Trace   string
+	}{
+		Status:  status,
+		Title:   http.StatusText(status),
+		Message: Message(e),
+		Code:    Code(e),
+		Debug:   rs.Debug,
+	}
+	if rs.Debug {
+		data.Trace = e.ErrorWithStackTrace()
+	}
+	return htmlTemplate.Execute(w, data)
+}
@@ -0,0 +1,82 @@
+package errors_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/oarkflow/errors"
+)
+
+func TestWriteErrorKeepsID(t *testing.T) {
+	prev := errors.DefaultOutbound
+	errors.DefaultOutbound = &errors.OutboundPolicy{UserMessage: true}
+	defer func() { errors.DefaultOutbound = prev }()
+
+	rs := errors.NewResponder()
+	rs.Headers.ErrorID = true
+	e := errors.NewNotFound(nil, "no user", "", false)
+	req := httptest.NewRequest("GET", "/", nil)
+	req = req.WithContext(errors.ContextWithOp(req.Context(), "users.Get"))
+	rec := httptest.NewRecorder()
+	rs.WriteError(rec, req, e)
+
+	var body struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if got := rec.Header().Get(errors.HeaderErrorID); got != e.ID() {
+		t.Errorf("%s = %q, want %q", errors.HeaderErrorID, got, e.ID())
+	}
+	if body.ID != e.ID() {
+		t.Errorf("body id = %q, want %q", body.ID, e.ID())
+	}
+}
+
+func TestZeroResponder(t *testing.T) {
+	rs := &errors.Responder{}
+	rec := httptest.NewRecorder()
+	rs.WriteError(rec, httptest.NewRequest("GET", "/", nil), errors.NewConflict(nil, "taken", "users.Create", false))
+	if rec.Code != 409 {
+		t.Errorf("status = %d, want 409", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != errors.MediaJSON {
+		t.Errorf("Content-Type = %q, want %q", ct, errors.MediaJSON)
+	}
+}
+
+func TestWriteErrorPublicByDefault(t *testing.T) {
+	cause := errors.New("sql: no rows in result set")
+	e := errors.NewInternal(cause, "load user row failed", "users.Get", true).
+		WithField("query", "SELECT * FROM users").
+		WithField(errors.MetaRequestID, "req-1")
+	for _, accept := range []string{errors.MediaJSON, errors.MediaXML, errors.MediaProblem} {
+		rs := errors.NewResponder()
+		rs.PublicMetadata = []string{errors.MetaRequestID}
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept", accept)
+		rec := httptest.NewRecorder()
+		rs.WriteError(rec, req, e)
+
+		body := rec.Body.String()
+		for _, leak := range []string{"sql: no rows", "load user row failed", "SELECT", "responder_test.go", "additional"} {
+			if strings.Contains(body, leak) {
+				t.Errorf("%s body holds %q: %s", accept, leak, body)
+			}
+		}
+		if accept == errors.MediaJSON && !strings.Contains(body, "req-1") {
+			t.Errorf("%s body lacks the public metadata: %s", accept, body)
+		}
+	}
+
+	rs := errors.NewResponder()
+	rs.Debug = true
+	rec := httptest.NewRecorder()
+	rs.WriteError(rec, httptest.NewRequest("GET", "/", nil), e)
+	if !strings.Contains(rec.Body.String(), "sql: no rows") {
+		t.Errorf("debug body lacks the cause: %s", rec.Body.String())
+	}
+}
@@ -0,0 +1,70 @@
+package errors
+
+// Result holds either a value or an Error.
+type Result[T any] struct {
+	value T
+	err   *Error
+}
+
+// Ok returns a successful Result holding v.
+func Ok[T any](v T) Result[T] {
+	return Result[T]{value: v}
+}
+
+// Err returns a failed Result holding err.
+func Err[T any](err *Error) Result[T] {
+	return Result[T]{err: err}
+}
+
+// IsOk reports whether the Result holds no error.
+func (r Result[T]) IsOk() bool {
+	return r.err == nil
+}
+
+// Unwrap returns the value and the error of the Result.
+func (r Result[T]) Unwrap() (T, *Error) {
+	return r.value, r.err
+}
+
+// Error returns the error of the Result as an error interface,
+// which is a true nil when the Result is successful.
+func (r Result[T]) Error() error {
+	if r.err == nil {
+		return nil
+	}
+	return r.err
+}
+
+// OrElse returns the value of the Result, or fallback if the
+// Result failed.
+func (r Result[T]) OrElse(fallback T) T {
+	if r.err != nil {
+		return fallback
+	}
+	return r.value
+}
+
+// Map applies fn to the value of a successful Result. Failed
+// Results are returned unchanged.
+func Map[T, U any](r Result[T], fn func(T) U) Result[U] {
+	if r.err != nil {
+		return Result[U]{err: r.err}
+	}
+	return Result[U]{value: fn(r.value)}
+}
+
+// Collect returns the values of the successful results and a
+// Multi holding the errors of the failed ones, or nil if all of
+// them succeeded.
+func Collect[T any](results ...Result[T]) ([]T, error) {
+	values := make([]T, 0, len(results))
+	var m Multi
+	for _, r := range results {
+		if r.err != nil {
+			m.Append(r.err)
+			continue
+		}
+		values = append(values, r.value)
+	}
+	return values, m.ErrorOrNil()
+}
@@ -0,0 +1,95 @@
+package errors
+
+import (
+	"context"
+	"time"
+)
+
+// MetaAttempts is the metadata key holding the number of
+// attempts made by a Retry before giving up.
+const MetaAttempts = "attempts"
+
+// Retry runs work until it succeeds, waiting between attempts.
+// Work which still fails is sent to the dead-letter sink.
+type Retry struct {
+	// Op is the operation of the errors returned by Do.
+	Op string
+	// MaxAttempts is the number of attempts, including the
+	// first one. Values below 1 mean 3.
+	MaxAttempts int
+	// Backoff is the initial delay between attempts, doubled
+	// after every attempt up to MaxBackoff. A longer delay given
+	// by RetryAfter takes precedence.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// RetryIf tells whether a failure is worth another attempt.
+	// All failures are retried when nil.
+	RetryIf func(err error) bool
+	// DeadLetter, if set, receives the error of work which could
+	// not be processed, with its payload in the metadata.
+	DeadLetter Sink
+}
+
+// Do calls fn until it succeeds, the attempts are exhausted, the
+// failure is not retryable, or ctx is done. It then returns a
+// MAXIMUMATTEMPTS Error wrapping the last failure, holding the
+// payload and the number of attempts in its metadata, after
+// writing it to the dead-letter sink.
+func (r *Retry) Do(ctx context.Context, payload any, fn func(ctx context.Context) error) error {
+	maxAttempts := r.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 3
+	}
+	backoff := r.Backoff
+	if backoff <= 0 {
+		backoff = 100 * time.Millisecond
+	}
+	maxBackoff := r.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = 10 * time.Second
+	}
+	var err error
+	attempts := 0
+	for attempts < maxAttempts {
+		attempts++
+		if err = fn(ctx); err == nil {
+			return nil
+		}
+		if attempts == maxAttempts || (r.RetryIf != nil && !r.RetryIf(err)) {
+			break
+		}
+		delay := backoff
+		if d, ok := RetryAfter(err); ok && d > delay {
+			delay = d
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return r.fail(ctx, err, payload, attempts)
+		}
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+	return r.fail(ctx, err, payload, attempts)
+}
+
+func (r *Retry) fail(ctx context.Context, err error, payload any, attempts int) *Error {
+	e := buildError(2, err, "", MAXIMUMATTEMPTS, r.Op)
+	e.WithField(MetaAttempts, attempts)
+	if payload != nil {
+		e.WithField(MetaPayload, payload)
+	}
+	notify(e)
+	if r.DeadLetter != nil {
+		// The work may have been abandoned because ctx is done,
+		// which must not prevent it from being persisted.
+		if ctx.Err() != nil {
+			ctx = context.Background()
+		}
+		_ = r.DeadLetter.Write(ctx, e)
+	}
+	return e
+}
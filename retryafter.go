@@ -0,0 +1,105 @@
+package errors
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// MetaRetryAfter is the metadata key holding the retry delay of
+// an error, as a whole number of seconds or an RFC 3339 time, so
+// that it reads the same once the error is decoded.
+const MetaRetryAfter = "retry_after"
+
+// WithRetryAfter records that the operation may be retried after d,
+// rounded up to the second, and returns the error for chaining.
+func (e *Error) WithRetryAfter(d time.Duration) *Error {
+	secs := int64(d / time.Second)
+	if d%time.Second > 0 {
+		secs++
+	}
+	if secs < 0 {
+		secs = 0
+	}
+	return e.WithField(MetaRetryAfter, secs)
+}
+
+// WithRetryAt records that the operation may be retried at t and
+// returns the error for chaining.
+func (e *Error) WithRetryAt(t time.Time) *Error {
+	return e.WithField(MetaRetryAfter, t.UTC().Format(time.RFC3339Nano))
+}
+
+// NewRateLimited returns an Error with a MAXIMUMATTEMPTS error
+// code which may be retried after retryAfter.
+func NewRateLimited(err error, message, op string, retryAfter time.Duration, disableErrorHandler ...bool) *Error {
+	e := buildError(1, err, message, MAXIMUMATTEMPTS, op)
+	e.Retryable = true
+	e.WithRetryAfter(retryAfter)
+	notify(e, disableErrorHandler...)
+	return e
+}
+
+// NewLockTimeout returns an Error with a EXPIRED error code for a
+// lock or lease which may be acquired again at retryAt.
+func NewLockTimeout(err error, message, op string, retryAt time.Time, disableErrorHandler ...bool) *Error {
+	e := buildError(1, err, message, EXPIRED, op)
+	e.Retryable = true
+	e.WithRetryAt(retryAt)
+	notify(e, disableErrorHandler...)
+	return e
+}
+
+// RetryAfter returns the retry delay recorded by the first Error
+// of the chain having one. Absolute times are converted to the
+// delay from now, never negative.
+func RetryAfter(err error) (time.Duration, bool) {
+	for _, e := range chainOf(err) {
+		v, ok := e.Meta(MetaRetryAfter)
+		if !ok {
+			continue
+		}
+		if d, ok := retryDelay(v); ok {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// retryDelay converts a MetaRetryAfter value, as recorded or as
+// decoded from JSON, YAML or a map, to the delay from now.
+func retryDelay(v any) (time.Duration, bool) {
+	var d time.Duration
+	switch t := v.(type) {
+	case int:
+		d = time.Duration(t) * time.Second
+	case int64:
+		d = time.Duration(t) * time.Second
+	case float64:
+		d = time.Duration(t * float64(time.Second))
+	case json.Number:
+		secs, err := t.Float64()
+		if err != nil {
+			return 0, false
+		}
+		d = time.Duration(secs * float64(time.Second))
+	case string:
+		if secs, err := strconv.ParseInt(t, 10, 64); err == nil {
+			d = time.Duration(secs) * time.Second
+		} else if at, err := time.Parse(time.RFC3339Nano, t); err == nil {
+			d = at.Sub(currentTime())
+		} else {
+			return 0, false
+		}
+	case time.Duration:
+		d = t
+	case time.Time:
+		d = t.Sub(currentTime())
+	default:
+		return 0, false
+	}
+	if d < 0 {
+		d = 0
+	}
+	return d, true
+}
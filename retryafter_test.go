@@ -0,0 +1,51 @@
+package errors_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/oarkflow/errors"
+	"github.com/oarkflow/errors/errtest"
+)
+
+func TestRetryAfterRoundTrip(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	errtest.SetClock(t, errtest.FixedClock(now))
+
+	tests := map[string]struct {
+		e    *errors.Error
+		want time.Duration
+	}{
+		"delay":      {errors.NewRateLimited(nil, "slow down", "api", 30*time.Second, true), 30 * time.Second},
+		"sub second": {errors.NewRateLimited(nil, "slow down", "api", 1500*time.Millisecond, true), 2 * time.Second},
+		"time":       {errors.NewLockTimeout(nil, "locked", "lock", now.Add(time.Minute), true), time.Minute},
+	}
+	for name, tt := range tests {
+		if d, ok := errors.RetryAfter(tt.e); !ok || d != tt.want {
+			t.Errorf("%s: RetryAfter = %v, %v, want %v", name, d, ok, tt.want)
+		}
+		b, err := json.Marshal(tt.e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded errors.Error
+		if err := json.Unmarshal(b, &decoded); err != nil {
+			t.Fatal(err)
+		}
+		if d, ok := errors.RetryAfter(&decoded); !ok || d != tt.want {
+			t.Errorf("%s: RetryAfter of the decoded error = %v, %v, want %v: %s", name, d, ok, tt.want, b)
+		}
+		m, err := tt.e.ToMap()
+		if err != nil {
+			t.Fatal(err)
+		}
+		fromMap, err := errors.FromMap(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d, ok := errors.RetryAfter(fromMap); !ok || d != tt.want {
+			t.Errorf("%s: RetryAfter of FromMap = %v, %v, want %v", name, d, ok, tt.want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// Safe calls f and returns its error. A panic in f is recovered
+// into an INTERNAL Error whose stack starts where the panic
+// occurred. A panic value which is an error is kept as the cause;
+// any other value is kept as the PanicValue of the Error.
+func Safe(f func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			e := panicError(r)
+			notify(e)
+			err = e
+		}
+	}()
+	return f()
+}
+
+// SafeV is like Safe for functions also returning a value. The
+// zero value is returned when f panics.
+func SafeV[T any](f func() (T, error)) (v T, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			var zero T
+			e := panicError(r)
+			notify(e)
+			v, err = zero, e
+		}
+	}()
+	return f()
+}
+
+// panicError converts a recovered panic value into an Error,
+// without notifying it. It must be called by the deferred function
+// which recovered it.
+func panicError(r any) *Error {
+	cause, ok := r.(error)
+	if !ok {
+		cause = fmt.Errorf("panic: %v", r)
+	}
+	// The cause is set afterwards, as a stack it may carry
+	// does not lead to the panic.
+	e := buildError(panicSkip(), nil, "", INTERNAL, "")
+	e.Err = cause
+	e.captureCause(cause)
+	if !ok {
+		e.PanicValue = r
+	}
+	// The stack starts one frame above the file line, on the
+	// runtime function raising the panic.
+	if len(e.pcs) > 1 {
+		e.pcs = e.pcs[1:]
+		e.Additional = e.frames(additionalDepth)
+	}
+	return e
+}
+
+// panicSkip returns the number of frames between the caller of
+// panicSkip and the function which panicked, skipping the
+// runtime frames raising the panic.
+func panicSkip() int {
+	inPanic := false
+	for i := 2; ; i++ {
+		pc, _, _, ok := runtime.Caller(i)
+		if !ok {
+			return 1
+		}
+		name := ""
+		if fn := runtime.FuncForPC(pc); fn != nil {
+			name = fn.Name()
+		}
+		if name == "runtime.gopanic" {
+			inPanic = true
+		} else if inPanic && !strings.HasPrefix(name, "runtime.") {
+			return i - 1
+		}
+	}
+}
+
+// panicValueJSON returns the JSON encoding of a panic value, or its
+// fmt rendering when it cannot be encoded, e.g. for channels,
+// functions and cyclic values.
+func panicValueJSON(v any) (out any) {
+	if v == nil {
+		return nil
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			out = fmt.Sprintf("%+v", v)
+		}
+	}()
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%+v", v)
+	}
+	return json.RawMessage(b)
+}
@@ -0,0 +1,275 @@
+package errors
+
+import (
+	"encoding/json"
+)
+
+// schemaField describes an encoded field for the schema
+// generators.
+type schemaField struct {
+	name     string
+	kind     string
+	required bool
+}
+
+// schemaFields lists the fields written by MarshalJSONWithOptions,
+// in order. Required fields are always written unless
+// MarshalOptions.OmitEmpty is set.
+func schemaFields(opts MarshalOptions) []schemaField {
+	fields := []schemaField{
+		{"v", "int", true},
+		{"id", "string", false},
+		{"code", "string", true},
+		{"top_code", "string", false},
+		{"message", "string", true},
+		{"operation", "string", true},
+		{"error", "string", true},
+		{"file_line", "string", true},
+		{"caller", "caller", false},
+		{"internal", "bool", true},
+		{"severity", "severity", false},
+		{"metadata", "metadata", false},
+		{"violations", "violations", false},
+		{"retryable", "bool", false},
+		{"occurrences", "long", false},
+		{"first_seen", "time", false},
+		{"last_seen", "time", false},
+		{"panic_value", "any", false},
+		{"notes", "notes", false},
+	}
+	if opts.MaxMessageLength > 0 || opts.MaxErrorLength > 0 || opts.MaxMetadataLength > 0 {
+		fields = append(fields, schemaField{"truncated", "lengths", false})
+	}
+	if !opts.OmitStack {
+		fields = append(fields, schemaField{StackFieldName, "stack", false})
+	}
+	if opts.Chain {
+		fields = append(fields, schemaField{"chain", "chain", false})
+	}
+	if opts.OmitEmpty {
+		for i := range fields {
+			fields[i].required = fields[i].name == "v"
+		}
+	}
+	return fields
+}
+
+func severityValues() []string {
+	values := make([]string, 0, len(severityNames))
+	for s := SeverityDebug; s <= SeverityCritical; s++ {
+		values = append(values, s.String())
+	}
+	return values
+}
+
+// JSONSchema returns a JSON Schema (draft 2020-12) describing the
+// errors encoded with opts.
+func JSONSchema(opts MarshalOptions) ([]byte, error) {
+	properties := make(map[string]any)
+	required := []string{}
+	for _, f := range schemaFields(opts) {
+		name := opts.name(f.name)
+		properties[name] = jsonSchemaType(f.kind, opts)
+		if f.required {
+			required = append(required, name)
+		}
+	}
+	return json.MarshalIndent(map[string]any{
+		"$schema":    "https://json-schema.org/draft/2020-12/schema",
+		"$id":        "https://github.com/oarkflow/errors/error.schema.json",
+		"title":      "Error",
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}, "", "  ")
+}
+
+func jsonSchemaType(kind string, opts MarshalOptions) map[string]any {
+	str := map[string]any{"type": "string"}
+	switch kind {
+	case "int", "long":
+		return map[string]any{"type": "integer"}
+	case "time":
+		return map[string]any{"type": "string", "format": "date-time"}
+	case "bool":
+		return map[string]any{"type": "boolean"}
+	case "severity":
+		return map[string]any{"type": "string", "enum": severityValues()}
+	case "metadata":
+		return map[string]any{"type": "object"}
+	case "any":
+		return map[string]any{}
+	case "lengths":
+		return map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "integer"}}
+	case "caller":
+		return map[string]any{"type": "object", "properties": map[string]any{
+			"package":  str,
+			"function": str,
+			"file":     str,
+			"line":     map[string]any{"type": "integer"},
+		}}
+	case "notes":
+		return map[string]any{"type": "array", "items": map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"text": str, "file_line": str},
+			"required":   []string{"text"},
+		}}
+	case "violations":
+		return map[string]any{"type": "array", "items": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"field": str, "rule": str, "param": str, "message": str,
+			},
+			"required": []string{"field", "message"},
+		}}
+	case "stack":
+		if StackJSONFormat == StackStrings {
+			return map[string]any{"type": "array", "items": str}
+		}
+		return map[string]any{"type": "array", "items": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"index":    map[string]any{"type": "integer"},
+				"function": str,
+				"file":     str,
+				"line":     map[string]any{"type": "integer"},
+			},
+			"required": []string{"index"},
+		}}
+	case "chain":
+		link := map[string]any{
+			opts.name("code"):      str,
+			opts.name("operation"): str,
+			opts.name("message"):   str,
+			opts.name("file_line"): str,
+		}
+		if !opts.OmitStack {
+			link[opts.name(StackFieldName)] = jsonSchemaType("stack", opts)
+		}
+		return map[string]any{"type": "array", "items": map[string]any{
+			"type":       "object",
+			"properties": link,
+		}}
+	}
+	return str
+}
+
+// AvroSchema returns an Avro record schema describing the errors
+// encoded with opts. Optional fields are unions with null. The
+// metadata is a map of primitive values; nested values must be
+// flattened before the error is written with Avro.
+func AvroSchema(opts MarshalOptions) ([]byte, error) {
+	fields := make([]map[string]any, 0, len(schemaFields(opts)))
+	g := avroGen{opts: opts, defined: make(map[string]bool)}
+	for _, f := range schemaFields(opts) {
+		t := g.avroType(f.kind)
+		field := map[string]any{"name": opts.name(f.name), "type": t}
+		if !f.required {
+			field["type"] = []any{"null", t}
+			field["default"] = nil
+		}
+		fields = append(fields, field)
+	}
+	return json.MarshalIndent(map[string]any{
+		"type":      "record",
+		"name":      "Error",
+		"namespace": "com.github.oarkflow.errors",
+		"fields":    fields,
+	}, "", "  ")
+}
+
+// avroGen emits named Avro types in full the first time only, as
+// Avro requires later uses to refer to them by name.
+type avroGen struct {
+	opts    MarshalOptions
+	defined map[string]bool
+}
+
+func (g avroGen) named(name string, t map[string]any) any {
+	if g.defined[name] {
+		return name
+	}
+	g.defined[name] = true
+	t["name"] = name
+	return t
+}
+
+func (g avroGen) avroType(kind string) any {
+	opts := g.opts
+	switch kind {
+	case "int", "long":
+		return kind
+	case "time":
+		return "string"
+	case "bool":
+		return "boolean"
+	case "severity":
+		return g.named("Severity", map[string]any{"type": "enum", "symbols": severityValues()})
+	case "metadata":
+		return map[string]any{"type": "map", "values": []string{"null", "boolean", "long", "double", "string"}}
+	case "any":
+		return []string{"null", "boolean", "long", "double", "string"}
+	case "lengths":
+		return map[string]any{"type": "map", "values": "long"}
+	case "caller":
+		return g.named("Caller", map[string]any{
+			"type": "record",
+			"fields": []map[string]any{
+				{"name": "package", "type": []string{"null", "string"}, "default": nil},
+				{"name": "function", "type": []string{"null", "string"}, "default": nil},
+				{"name": "file", "type": []string{"null", "string"}, "default": nil},
+				{"name": "line", "type": []string{"null", "int"}, "default": nil},
+			},
+		})
+	case "notes":
+		return map[string]any{"type": "array", "items": g.named("Note", map[string]any{
+			"type": "record",
+			"fields": []map[string]any{
+				{"name": "text", "type": "string"},
+				{"name": "file_line", "type": []string{"null", "string"}, "default": nil},
+			},
+		})}
+	case "violations":
+		return map[string]any{"type": "array", "items": g.named("FieldViolation", map[string]any{
+			"type": "record",
+			"fields": []map[string]any{
+				{"name": "field", "type": "string"},
+				{"name": "rule", "type": []string{"null", "string"}, "default": nil},
+				{"name": "param", "type": []string{"null", "string"}, "default": nil},
+				{"name": "message", "type": "string"},
+			},
+		})}
+	case "stack":
+		if StackJSONFormat == StackStrings {
+			return map[string]any{"type": "array", "items": "string"}
+		}
+		return map[string]any{"type": "array", "items": g.named("Trace", map[string]any{
+			"type": "record",
+			"fields": []map[string]any{
+				{"name": "index", "type": "int"},
+				{"name": "function", "type": []string{"null", "string"}, "default": nil},
+				{"name": "file", "type": []string{"null", "string"}, "default": nil},
+				{"name": "line", "type": []string{"null", "int"}, "default": nil},
+			},
+		})}
+	case "chain":
+		fields := []map[string]any{
+			{"name": opts.name("code"), "type": "string"},
+			{"name": opts.name("operation"), "type": []string{"null", "string"}, "default": nil},
+			{"name": opts.name("message"), "type": []string{"null", "string"}, "default": nil},
+			{"name": opts.name("file_line"), "type": []string{"null", "string"}, "default": nil},
+		}
+		if !opts.OmitStack {
+			fields = append(fields, map[string]any{
+				"name":    opts.name(StackFieldName),
+				"type":    []any{"null", g.avroType("stack")},
+				"default": nil,
+			})
+		}
+		return map[string]any{"type": "array", "items": g.named("ChainLink", map[string]any{
+			"type":   "record",
+			"fields": fields,
+		})}
+	}
+	return "string"
+}
@@ -0,0 +1,65 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+)
+
+// SecretMask replaces the secret metadata values wherever the
+// error is rendered.
+const SecretMask = "***"
+
+// Secret holds a sensitive metadata value, such as a password or
+// a token. It is formatted and encoded as SecretMask, so it does
+// not leak to messages, logs or JSON, while Value returns the
+// original value for in-process inspection.
+type Secret struct {
+	value any
+}
+
+// NewSecret returns a Secret holding value.
+func NewSecret(value any) Secret {
+	return Secret{value: value}
+}
+
+// Value returns the secret value.
+func (s Secret) Value() any {
+	return s.value
+}
+
+// String returns SecretMask.
+func (s Secret) String() string {
+	return SecretMask
+}
+
+// Format writes SecretMask whatever the verb.
+func (s Secret) Format(f fmt.State, _ rune) {
+	_, _ = io.WriteString(f, SecretMask)
+}
+
+// MarshalText returns SecretMask.
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(SecretMask), nil
+}
+
+// WithSecretField adds a metadata entry whose value is always
+// rendered as SecretMask. Use Reveal to read it back.
+func (e *Error) WithSecretField(key string, value any) *Error {
+	return e.WithField(key, NewSecret(value))
+}
+
+// Reveal returns the metadata value stored under key, unwrapping
+// it if it is a Secret.
+func (e *Error) Reveal(key string) (any, bool) {
+	v, ok := e.Meta(key)
+	if s, isSecret := v.(Secret); isSecret {
+		return s.value, ok
+	}
+	return v, ok
+}
+
+// SecretMeta adds a metadata entry whose value is always rendered
+// as SecretMask.
+func (b *Builder) SecretMeta(key string, value any) *Builder {
+	return b.Meta(key, NewSecret(value))
+}
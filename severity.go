@@ -0,0 +1,74 @@
+package errors
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity is the importance of an error. The zero value means
+// the severity has not been set.
+type Severity int
+
+// Error severities, from least to most important.
+const (
+	SeverityDebug Severity = iota + 1
+	SeverityInfo
+	SeverityWarning
+	SeverityError
+	SeverityCritical
+)
+
+var severityNames = map[Severity]string{
+	SeverityDebug:    "debug",
+	SeverityInfo:     "info",
+	SeverityWarning:  "warning",
+	SeverityError:    "error",
+	SeverityCritical: "critical",
+}
+
+// String returns the lower case name of the severity.
+func (s Severity) String() string {
+	if name, ok := severityNames[s]; ok {
+		return name
+	}
+	return ""
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *Severity) UnmarshalText(text []byte) error {
+	name := strings.ToLower(string(text))
+	if name == "" {
+		*s = 0
+		return nil
+	}
+	for sev, n := range severityNames {
+		if n == name {
+			*s = sev
+			return nil
+		}
+	}
+	return fmt.Errorf("errors: unknown severity %q", text)
+}
+
+// SeverityOf returns the severity of the first Error in the
+// chain having one. Otherwise, INTERNAL errors default to
+// SeverityError and every other error to SeverityWarning.
+func SeverityOf(err error) Severity {
+	if err == nil {
+		return 0
+	}
+	for _, e := range chainOf(err) {
+		if e.Severity != 0 {
+			return e.Severity
+		}
+	}
+	if Code(err) == INTERNAL {
+		return SeverityError
+	}
+	return SeverityWarning
+}
@@ -0,0 +1,120 @@
+package errors
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// Shutdown collects the errors of the subsystems closed during a
+// graceful shutdown:
+//
+//	s := errors.NewShutdown(5 * time.Second)
+//	s.Close(ctx, "http.Shutdown", server.Shutdown)
+//	s.CloseIO("db.Close", db)
+//	errors.Exit(s.Err())
+//
+// It is safe for concurrent use, so subsystems may be closed in
+// parallel.
+type Shutdown struct {
+	// Timeout bounds every close call. Zero means no limit.
+	Timeout time.Duration
+
+	mu   sync.Mutex
+	errs Multi
+}
+
+// NewShutdown returns a Shutdown bounding every close call by
+// timeout.
+func NewShutdown(timeout time.Duration) *Shutdown {
+	return &Shutdown{Timeout: timeout}
+}
+
+// Close calls fn with ctx, bounded by the Timeout, and records its
+// failure wrapped in an Error for op. A call still running when the
+// Timeout or the deadline of ctx elapses is abandoned and recorded
+// as a TIMEOUT, and one still running when ctx is canceled as an
+// UNKNOWN "shutdown canceled" Error, like the canceled RPCs. The
+// recorded error is returned.
+func (s *Shutdown) Close(ctx context.Context, op string, fn func(ctx context.Context) error) error {
+	return s.close(ctx, 1, op, fn)
+}
+
+func (s *Shutdown) close(ctx context.Context, skip int, op string, fn func(ctx context.Context) error) error {
+	if fn == nil {
+		return nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- fn(ctx)
+	}()
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if err == nil {
+		return nil
+	}
+	code, message := DefaultCode, "shutdown failed"
+	switch {
+	case Is(err, context.DeadlineExceeded):
+		code, message = TIMEOUT, "shutdown timed out"
+	case Is(err, context.Canceled):
+		code, message = UNKNOWN, "shutdown canceled"
+	}
+	e := buildError(skip+1, err, message, code, op)
+	e.Context = ctx
+	notify(e)
+	s.mu.Lock()
+	s.errs.Append(e)
+	s.mu.Unlock()
+	return e
+}
+
+// CloseIO closes c like Close, for closers without a context.
+func (s *Shutdown) CloseIO(op string, c io.Closer) error {
+	if c == nil {
+		return nil
+	}
+	return s.close(context.Background(), 1, op, func(context.Context) error {
+		return c.Close()
+	})
+}
+
+// Errors returns the recorded errors, in the order they occurred.
+func (s *Shutdown) Errors() []error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]error(nil), s.errs.Errors...)
+}
+
+// Err returns the recorded errors as a Multi, or nil if every
+// subsystem closed cleanly.
+func (s *Shutdown) Err() error {
+	m := &Multi{Errors: s.Errors()}
+	return m.ErrorOrNil()
+}
+
+// ExitCode returns the process exit code of the shutdown: 0 if
+// every subsystem closed cleanly, otherwise the highest ExitCode
+// of the recorded errors.
+func (s *Shutdown) ExitCode() int {
+	code := 0
+	for _, err := range s.Errors() {
+		if c := ExitCode(err); c > code {
+			code = c
+		}
+	}
+	return code
+}
@@ -0,0 +1,39 @@
+package errors_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/oarkflow/errors"
+)
+
+func block(ctx context.Context) error {
+	<-ctx.Done()
+	time.Sleep(time.Millisecond)
+	return nil
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	s := errors.NewShutdown(time.Millisecond)
+	err := s.Close(context.Background(), "http.Shutdown", block)
+	if got := errors.Code(err); got != errors.TIMEOUT {
+		t.Errorf("code = %q, want %q", got, errors.TIMEOUT)
+	}
+}
+
+func TestShutdownCanceled(t *testing.T) {
+	s := errors.NewShutdown(time.Minute)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := s.Close(ctx, "http.Shutdown", block)
+	if got := errors.Code(err); got == errors.TIMEOUT {
+		t.Errorf("canceled shutdown reported as %q", got)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if got := errors.Message(err); got != "shutdown canceled" {
+		t.Errorf("message = %q", got)
+	}
+}
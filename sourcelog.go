@@ -0,0 +1,89 @@
+package errors
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// maxSourceLogHistory is the number of fingerprints above which the
+// expired entries of a SourceLogger are pruned.
+const maxSourceLogHistory = 1024
+
+// SourceLogger logs the errors once where they are created, so that
+// errors swallowed upstream remain visible. Set its Observe method,
+// or chain it, as the DefaultErrorCallbackHandler. The Logger is any
+// Reporter, such as a LogWriter or a ReporterFunc calling a zap or
+// slog logger with the context of the error. Every logged error is
+// given an ID first, so that later log lines carrying err.ID() can
+// be correlated with the source line.
+type SourceLogger struct {
+	// Logger receives the logged errors.
+	Logger Reporter
+	// Policy, if set, decides which errors are logged: those it does
+	// not report are skipped.
+	Policy *Policy
+	// Window is the period during which the errors of a fingerprint
+	// already logged are skipped. Zero logs every error.
+	Window time.Duration
+
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+// NewSourceLogger returns a SourceLogger sending the errors to
+// logger, at most once per fingerprint during window.
+func NewSourceLogger(logger Reporter, window time.Duration) *SourceLogger {
+	return &SourceLogger{Logger: logger, Window: window}
+}
+
+// Observe logs err unless the policy does not report it or its
+// fingerprint was logged during the window. It can be used as an
+// ErrorCallbackHandler.
+func (s *SourceLogger) Observe(err *Error) {
+	if err == nil || s.Logger == nil {
+		return
+	}
+	err.ID()
+	e := err
+	if s.Policy != nil {
+		var d Decision
+		if e, d = s.Policy.Apply(err); e == nil || !d.Report {
+			return
+		}
+	}
+	if !s.first(e.Fingerprint()) {
+		return
+	}
+	ctx := context.Background()
+	if e.Context != nil {
+		ctx = e.Context
+	}
+	_ = s.Logger.Report(ctx, e)
+}
+
+// first records the fingerprint and reports whether it was not seen
+// during the window.
+func (s *SourceLogger) first(fingerprint string) bool {
+	if s.Window <= 0 {
+		return true
+	}
+	now := currentTime()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if last, ok := s.seen[fingerprint]; ok && now.Sub(last) < s.Window {
+		return false
+	}
+	if s.seen == nil {
+		s.seen = make(map[string]time.Time)
+	}
+	if len(s.seen) >= maxSourceLogHistory {
+		for fp, last := range s.seen {
+			if now.Sub(last) >= s.Window {
+				delete(s.seen, fp)
+			}
+		}
+	}
+	s.seen[fingerprint] = now
+	return true
+}
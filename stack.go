@@ -0,0 +1,245 @@
+package errors
+
+import (
+	"encoding/json"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// StackFormat selects how stack frames are serialized to JSON.
+type StackFormat int
+
+const (
+	// StackObjects serializes frames as an array of Trace objects.
+	StackObjects StackFormat = iota
+	// StackStrings serializes frames as an array of strings
+	// formatted by Trace.String.
+	StackStrings
+)
+
+// additionalDepth is the number of frames kept in Additional.
+const additionalDepth = 2
+
+var (
+	// StackDepth is the maximum number of program counters
+	// captured when an error is created.
+	StackDepth = 32
+	// StackFieldName is the JSON field holding the stack frames.
+	StackFieldName = "additional"
+	// StackJSONFormat is the JSON shape of the stack frames.
+	StackJSONFormat = StackObjects
+	// ReuseCauseStack makes wrapping an error which already carries
+	// a stack, such as an Error or an error of github.com/pkg/errors,
+	// reuse that stack instead of capturing one at the wrap site.
+	ReuseCauseStack = true
+)
+
+// Frames returns the function, file and line of every frame
+// captured when the error was created, up to StackDepth. It only
+// resolves the local program counters, and returns nil for errors
+// decoded from another process; see StackFrames.
+func (e *Error) Frames() []Trace {
+	if e == nil {
+		return nil
+	}
+	return e.frames(-1)
+}
+
+// frames resolves the first max frames of the program counters,
+// or all of them if max is negative, including the inlined calls.
+func (e *Error) frames(max int) []Trace {
+	if len(e.pcs) == 0 || max == 0 {
+		return nil
+	}
+	n := max
+	if n < 0 {
+		n = len(e.pcs)
+	}
+	frames := make([]Trace, 0, n)
+	rFrames := runtime.CallersFrames(e.pcs)
+	for max < 0 || len(frames) < max {
+		frame, more := rFrames.Next()
+		if frame.PC != 0 || frame.Function != "" {
+			frames = append(frames, Trace{
+				Index:    len(frames),
+				Function: frame.Function,
+				File:     frame.File,
+				Line:     frame.Line,
+			})
+		}
+		if !more {
+			break
+		}
+	}
+	return frames
+}
+
+// NewFromFrames returns an Error built from frames recorded
+// elsewhere, such as in logs or another tracing format, instead of
+// the runtime stack. The frames are kept in Additional, reindexed,
+// and the first one gives the file line and Caller. Like Light, it
+// does not call the DefaultErrorCallbackHandler.
+func NewFromFrames(code, message, op string, frames []Trace, cause error) *Error {
+	e := &Error{
+		Code:      code,
+		Message:   message,
+		Operation: op,
+		Err:       cause,
+		Internal:  code == INTERNAL,
+	}
+	if len(frames) > 0 {
+		e.Additional = make(StackTrace, len(frames))
+		for i, t := range frames {
+			t.Index = i
+			e.Additional[i] = t
+		}
+		t := frames[0]
+		e.fileLine = t.File + ":" + strconv.Itoa(t.Line)
+		e.caller = Caller{File: t.File, Line: t.Line}
+		e.caller.Package, e.caller.Function = splitFuncName(t.Function)
+	}
+	if cause != nil && cyclic(cause) {
+		e.Err = ErrCyclicWrap
+		e.Metadata = map[string]any{MetaCyclic: true}
+	} else if cause != nil {
+		e.captureCause(cause)
+	}
+	recordOp(op)
+	return e
+}
+
+// StackFrames returns the frames of Frames when the error holds
+// local program counters, or else a copy of the serialized frames
+// of Additional, so decoded errors still render their traces.
+func (e *Error) StackFrames() []Trace {
+	if e == nil {
+		return nil
+	}
+	if frames := e.Frames(); len(frames) > 0 {
+		return frames
+	}
+	if len(e.Additional) == 0 {
+		return nil
+	}
+	return append([]Trace(nil), e.Additional...)
+}
+
+// HasLocalStack reports whether the error holds program counters
+// captured in this process, as opposed to frames decoded from JSON,
+// YAML, protobuf or the binary encoding.
+func (e *Error) HasLocalStack() bool {
+	return e != nil && len(e.pcs) > 0
+}
+
+// callers returns the program counters of the stack skip frames
+// above the caller of callers, up to StackDepth.
+func callers(skip int) []uintptr {
+	pcs := make([]uintptr, StackDepth)
+	return pcs[:runtime.Callers(skip+2, pcs)]
+}
+
+// causeStack returns the program counters of the nearest error of
+// the chain of err carrying a stack, or nil if there is none or
+// ReuseCauseStack is unset.
+func causeStack(err error) []uintptr {
+	if !ReuseCauseStack {
+		return nil
+	}
+	for i := 0; err != nil && i < maxChainLength; i++ {
+		if pcs := stackOf(err); len(pcs) > 0 {
+			return pcs
+		}
+		err = Unwrap(err)
+	}
+	return nil
+}
+
+// stackOf returns the program counters carried by err itself.
+func stackOf(err error) []uintptr {
+	switch s := err.(type) {
+	case *Error:
+		if s == nil {
+			return nil
+		}
+		return s.pcs
+	case interface{ ProgramCounters() []uintptr }:
+		return s.ProgramCounters()
+	case interface{ Callers() []uintptr }:
+		return s.Callers()
+	}
+	// github.com/pkg/errors exposes StackTrace() errors.StackTrace,
+	// a slice of frames holding program counters.
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() {
+		return nil
+	}
+	t := m.Type()
+	if t.NumIn() != 0 || t.NumOut() != 1 || t.Out(0).Kind() != reflect.Slice || t.Out(0).Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+	frames := m.Call(nil)[0]
+	pcs := make([]uintptr, frames.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(frames.Index(i).Uint())
+	}
+	return pcs
+}
+
+func marshalStack(t StackTrace) ([]byte, error) {
+	if StackJSONFormat == StackStrings {
+		return json.Marshal(t.StringArray())
+	}
+	return json.Marshal(t)
+}
+
+// unmarshalStack decodes the stack frames stored under name in
+// either of the StackFormat shapes.
+func unmarshalStack(data []byte, name string) (StackTrace, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	raw, ok := fields[name]
+	if !ok || string(raw) == "null" {
+		return nil, nil
+	}
+	var t StackTrace
+	if err := json.Unmarshal(raw, &t); err == nil {
+		return t, nil
+	}
+	var lines []string
+	if err := json.Unmarshal(raw, &lines); err != nil {
+		return nil, err
+	}
+	t = make(StackTrace, 0, len(lines))
+	for i, line := range lines {
+		t = append(t, parseTrace(i, line))
+	}
+	return t, nil
+}
+
+// parseTrace parses a frame formatted by Trace.String.
+func parseTrace(index int, s string) Trace {
+	t := Trace{Index: index}
+	if strings.HasPrefix(s, "#") {
+		if i := strings.IndexByte(s, ' '); i > 0 {
+			if n, err := strconv.Atoi(s[1:i]); err == nil {
+				t.Index = n
+			}
+			s = s[i+1:]
+		}
+	}
+	loc := s
+	if i := strings.IndexByte(s, ' '); i >= 0 {
+		loc, t.Function = s[:i], s[i+1:]
+	}
+	if i := strings.LastIndexByte(loc, ':'); i >= 0 {
+		t.File = loc[:i]
+		t.Line, _ = strconv.Atoi(loc[i+1:])
+	} else {
+		t.File = loc
+	}
+	return t
+}
@@ -0,0 +1,26 @@
+package errors_test
+
+import (
+	"testing"
+
+	"github.com/oarkflow/errors"
+)
+
+func TestAdditionalIsStackPrefix(t *testing.T) {
+	e := errors.NewInternal(nil, "failed", "op", true)
+	frames := e.Frames()
+	if len(e.Additional) != 2 || len(frames) < 2 {
+		t.Fatalf("got %d additional frames of %d", len(e.Additional), len(frames))
+	}
+	for i, f := range e.Additional {
+		if f != frames[i] {
+			t.Errorf("Additional[%d] = %v, want %v", i, f, frames[i])
+		}
+	}
+}
+
+func BenchmarkNewInternal(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		errors.NewInternal(nil, "failed", "op", true)
+	}
+}
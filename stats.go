@@ -0,0 +1,181 @@
+package errors
+
+import (
+	"sync"
+	"time"
+)
+
+// StatsEntry holds the statistics of a code or an operation.
+type StatsEntry struct {
+	Count int64 `json:"count"`
+	// Rate is the number of errors per second since Since.
+	Rate      float64   `json:"rate"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// StatsSnapshot is a copy of the statistics collected since
+// Since, suitable for a health payload.
+type StatsSnapshot struct {
+	Since time.Time             `json:"since"`
+	Total int64                 `json:"total"`
+	Codes map[string]StatsEntry `json:"codes"`
+	Ops   map[string]StatsEntry `json:"operations"`
+}
+
+// Occurrence is an error recorded by a StatsCollector, with the
+// time it was seen.
+type Occurrence struct {
+	Time  time.Time
+	Error *Error
+}
+
+// RecentOccurrences is the number of the last occurrences kept by
+// a StatsCollector, see Occurrences. Zero keeps none.
+var RecentOccurrences = 1024
+
+// StatsCollector counts created errors per code and operation,
+// and keeps the RecentOccurrences last ones. Its Handler is meant
+// to be set, or chained, as the DefaultErrorCallbackHandler.
+type StatsCollector struct {
+	mu     sync.Mutex
+	since  time.Time
+	total  int64
+	codes  map[string]*StatsEntry
+	ops    map[string]*StatsEntry
+	recent []Occurrence
+	next   int
+}
+
+// DefaultStats is the collector read by Stats.
+var DefaultStats = NewStatsCollector()
+
+// NewStatsCollector returns an empty StatsCollector.
+func NewStatsCollector() *StatsCollector {
+	s := &StatsCollector{}
+	s.Reset()
+	return s
+}
+
+// Stats returns a snapshot of DefaultStats.
+func Stats() StatsSnapshot {
+	return DefaultStats.Snapshot()
+}
+
+// StatsHandler returns an ErrorCallbackHandler recording every
+// created error in DefaultStats.
+func StatsHandler() ErrorCallbackHandler {
+	return DefaultStats.Handler
+}
+
+// Handler records the error. It implements ErrorCallbackHandler.
+func (s *StatsCollector) Handler(err *Error) {
+	if err == nil {
+		return
+	}
+	s.recordError(err, currentTime())
+}
+
+// recordError counts err seen at t and keeps it among the recent
+// occurrences.
+func (s *StatsCollector) recordError(err *Error, t time.Time) {
+	s.Record(err.Code, err.Operation, t)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o := Occurrence{Time: t, Error: err}
+	switch {
+	case RecentOccurrences <= 0:
+		s.recent, s.next = nil, 0
+	case len(s.recent) < RecentOccurrences:
+		s.recent = append(s.recent, o)
+	default:
+		if s.next >= len(s.recent) {
+			s.next = 0
+		}
+		s.recent[s.next] = o
+		s.next++
+	}
+}
+
+// Occurrences returns the recent occurrences, oldest first.
+func (s *StatsCollector) Occurrences() []Occurrence {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Occurrence, 0, len(s.recent))
+	if s.next < len(s.recent) {
+		out = append(out, s.recent[s.next:]...)
+	}
+	return append(out, s.recent[:s.next]...)
+}
+
+// Record counts an error of the code and operation seen at t.
+// An empty code or operation is not counted on its own, nor are
+// new operations once MaxOps operations are counted.
+func (s *StatsCollector) Record(code, op string, t time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.total++
+	if code != "" {
+		record(s.codes, code, t)
+	}
+	if _, ok := s.ops[op]; op != "" && (ok || len(s.ops) < MaxOps) {
+		record(s.ops, op, t)
+	}
+}
+
+func record(entries map[string]*StatsEntry, key string, t time.Time) {
+	entry, ok := entries[key]
+	if !ok {
+		entry = &StatsEntry{}
+		entries[key] = entry
+	}
+	entry.Count++
+	if entry.FirstSeen.IsZero() || t.Before(entry.FirstSeen) {
+		entry.FirstSeen = t
+	}
+	if t.After(entry.LastSeen) {
+		entry.LastSeen = t
+	}
+}
+
+// Snapshot returns a copy of the statistics with the rates
+// computed up to now.
+func (s *StatsCollector) Snapshot() StatsSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	elapsed := currentTime().Sub(s.since).Seconds()
+	return StatsSnapshot{
+		Since: s.since,
+		Total: s.total,
+		Codes: snapshotEntries(s.codes, elapsed),
+		Ops:   snapshotEntries(s.ops, elapsed),
+	}
+}
+
+func snapshotEntries(entries map[string]*StatsEntry, elapsed float64) map[string]StatsEntry {
+	out := make(map[string]StatsEntry, len(entries))
+	for k, entry := range entries {
+		e := *entry
+		if elapsed > 0 {
+			e.Rate = float64(e.Count) / elapsed
+		}
+		out[k] = e
+	}
+	return out
+}
+
+// Reset clears the statistics and restarts the rate period.
+func (s *StatsCollector) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.since = currentTime()
+	s.total = 0
+	s.codes = make(map[string]*StatsEntry)
+	s.ops = make(map[string]*StatsEntry)
+	s.recent, s.next = nil, 0
+}
+
+// ResetStats resets DefaultStats.
+func ResetStats() {
+	DefaultStats.Reset()
+}
@@ -0,0 +1,55 @@
+package errors
+
+import (
+	"net/http"
+	"sync"
+)
+
+type statusOverride struct {
+	target error
+	status int
+}
+
+var (
+	statusOverridesMu sync.RWMutex
+	statusOverrides   []statusOverride
+)
+
+// RegisterHTTPStatus makes HTTPStatus return status for errors
+// matching target with Is, such as context.Canceled or
+// sql.ErrNoRows, whatever Errors wrap them. Later registrations
+// of the same target replace earlier ones.
+func RegisterHTTPStatus(target error, status int) {
+	statusOverridesMu.Lock()
+	defer statusOverridesMu.Unlock()
+	for i, o := range statusOverrides {
+		if o.target == target {
+			statusOverrides[i].status = status
+			return
+		}
+	}
+	statusOverrides = append(statusOverrides, statusOverride{target, status})
+}
+
+// HTTPStatus returns the HTTP status code of err: the status
+// registered with RegisterHTTPStatus for an error of its chain,
+// otherwise the status of the nearest Error, otherwise 500. If
+// err is nil, HTTPStatus returns 200.
+func HTTPStatus(err error) int {
+	if IsNil(err) {
+		return http.StatusOK
+	}
+	statusOverridesMu.RLock()
+	for _, o := range statusOverrides {
+		if Is(err, o.target) {
+			statusOverridesMu.RUnlock()
+			return o.status
+		}
+	}
+	statusOverridesMu.RUnlock()
+	var e *Error
+	if As(err, &e) && e != nil {
+		return e.HTTPStatusCode()
+	}
+	return http.StatusInternalServerError
+}
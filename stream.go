@@ -0,0 +1,83 @@
+package errors
+
+import (
+	"io"
+	"net/http"
+	"sync"
+)
+
+// WebSocket close codes defined by RFC 6455.
+const (
+	WSCloseNormal         = 1000
+	WSCloseInvalidPayload = 1007
+	WSClosePolicy         = 1008
+	WSCloseInternal       = 1011
+	WSCloseTryAgainLater  = 1013
+)
+
+var (
+	wsCloseCodesMu sync.RWMutex
+	wsCloseCodes   = map[string]int{
+		INVALID:           WSCloseInvalidPayload,
+		CONFLICT:          WSClosePolicy,
+		NOTFOUND:          WSClosePolicy,
+		EXPIRED:           WSClosePolicy,
+		MAXIMUMATTEMPTS:   WSClosePolicy,
+		INTERNAL:          WSCloseInternal,
+		UNKNOWN:           WSCloseInternal,
+		UNAVAILABLE:       WSCloseTryAgainLater,
+		FORBIDDEN:         WSClosePolicy,
+		TIMEOUT:           WSCloseTryAgainLater,
+		RESOURCEEXHAUSTED: WSCloseTryAgainLater,
+	}
+)
+
+// RegisterWSCloseCode sets the WebSocket close code returned by
+// WSCloseCode for errors with the given code.
+func RegisterWSCloseCode(code string, closeCode int) {
+	wsCloseCodesMu.Lock()
+	defer wsCloseCodesMu.Unlock()
+	wsCloseCodes[code] = closeCode
+}
+
+// WSCloseCode returns the RFC 6455 close code for the error:
+// WSCloseNormal when err is nil, the close code of its code or
+// of the nearest parent code, otherwise WSCloseInternal.
+func WSCloseCode(err error) int {
+	if err == nil {
+		return WSCloseNormal
+	}
+	wsCloseCodesMu.RLock()
+	defer wsCloseCodesMu.RUnlock()
+	for code := Code(err); code != ""; code = ParentCode(code) {
+		if c, ok := wsCloseCodes[code]; ok {
+			return c
+		}
+	}
+	return WSCloseInternal
+}
+
+// WriteSSE writes the error as a server-sent event of type
+// "error" whose data is the JSON encoded error, and flushes w
+// if it is an http.Flusher.
+func WriteSSE(w io.Writer, err error) error {
+	e := From(err)
+	if e == nil {
+		return nil
+	}
+	data, mErr := e.MarshalJSON()
+	if mErr != nil {
+		return mErr
+	}
+	frame := make([]byte, 0, len(data)+20)
+	frame = append(frame, "event: error\ndata: "...)
+	frame = append(frame, data...)
+	frame = append(frame, "\n\n"...)
+	if _, wErr := w.Write(frame); wErr != nil {
+		return wErr
+	}
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package errors
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// ErrorSummary is the short form of an error stored by its SQL
+// Valuer: the code, message, operation, fingerprint and ID, without
+// stack or metadata, e.g. for audit tables.
+type ErrorSummary struct {
+	Code        string `json:"code"`
+	Message     string `json:"message"`
+	Operation   string `json:"operation,omitempty"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+	ID          string `json:"id,omitempty"`
+}
+
+// Summary returns the summary of err. Errors which are not an
+// Error only get their code and message. A nil err returns the
+// zero summary, stored as NULL.
+func Summary(err error) ErrorSummary {
+	if IsNil(err) {
+		return ErrorSummary{}
+	}
+	s := ErrorSummary{Code: Code(err), Message: Message(err)}
+	var e *Error
+	if As(err, &e) && e != nil {
+		s.Operation = e.Operation
+		s.Fingerprint = e.Fingerprint()
+		s.ID = e.ID()
+	}
+	return s
+}
+
+// Value implements the driver.Valuer interface, storing the
+// summary as JSON, or NULL for the zero summary.
+func (s ErrorSummary) Value() (driver.Value, error) {
+	if s == (ErrorSummary{}) {
+		return nil, nil
+	}
+	return json.Marshal(s)
+}
+
+// Scan implements the sql.Scanner interface, decoding the JSON
+// stored in a []byte or string column. NULL scans to the zero
+// summary.
+func (s *ErrorSummary) Scan(value any) error {
+	if s == nil {
+		return fmt.Errorf("scan into nil *errors.ErrorSummary")
+	}
+	switch v := value.(type) {
+	case nil:
+		*s = ErrorSummary{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, s)
+	case string:
+		return json.Unmarshal([]byte(v), s)
+	}
+	return fmt.Errorf("scan not supported for *errors.ErrorSummary")
+}
+
+// Err returns the summary as an Error without stack, or nil for
+// the zero summary.
+func (s ErrorSummary) Err() *Error {
+	if s == (ErrorSummary{}) {
+		return nil
+	}
+	e := Light(s.Code, s.Message, s.Operation)
+	e.id = s.ID
+	return e
+}
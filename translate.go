@@ -0,0 +1,81 @@
+package errors
+
+import (
+	"sync"
+)
+
+// TranslateOption configures a translation registered with
+// Translate.
+type TranslateOption func(*translation)
+
+// TranslateMessage sets the message of the translated errors.
+func TranslateMessage(message string) TranslateOption {
+	return func(t *translation) { t.message = message }
+}
+
+// TranslateRetryable marks the translated errors as retryable.
+func TranslateRetryable() TranslateOption {
+	return func(t *translation) { t.retryable = true }
+}
+
+// TranslateSeverity sets the severity of the translated errors.
+func TranslateSeverity(s Severity) TranslateOption {
+	return func(t *translation) { t.severity = s }
+}
+
+type translation struct {
+	matcher   func(error) bool
+	code      string
+	message   string
+	retryable bool
+	severity  Severity
+}
+
+var (
+	translationsMu sync.RWMutex
+	translations   []translation
+)
+
+// Translate teaches From and Classify the code of the external
+// errors for which matcher returns true, so that the errors of
+// dependencies can be mapped declaratively:
+//
+//	errors.Translate(func(err error) bool {
+//		return errors.Is(err, sql.ErrNoRows)
+//	}, errors.NOTFOUND, errors.TranslateMessage("Record not found."))
+//
+// Translations are consulted in the order they were registered,
+// before the classifiers, and the first match wins.
+func Translate(matcher func(error) bool, code string, opts ...TranslateOption) {
+	if matcher == nil {
+		return
+	}
+	t := translation{matcher: matcher, code: code}
+	for _, opt := range opts {
+		opt(&t)
+	}
+	translationsMu.Lock()
+	defer translationsMu.Unlock()
+	translations = append(translations, t)
+}
+
+// translate returns the first translation matching err.
+func translate(err error) (translation, bool) {
+	translationsMu.RLock()
+	defer translationsMu.RUnlock()
+	for _, t := range translations {
+		if t.matcher(err) {
+			return t, true
+		}
+	}
+	return translation{}, false
+}
+
+// apply sets the code and the options of the translation on e.
+func (t translation) apply(e *Error) {
+	e.Code = t.code
+	e.Internal = t.code == INTERNAL
+	e.Message = t.message
+	e.Retryable = t.retryable
+	e.Severity = t.severity
+}
@@ -0,0 +1,102 @@
+package errors
+
+import (
+	"strconv"
+	"strings"
+)
+
+// maxTreeDepth bounds the depth of error trees, protecting
+// renderers from cyclic chains.
+const maxTreeDepth = 64
+
+// TreeNode is a node of the tree formed by an error chain, where
+// Multi errors and joined errors have several children.
+type TreeNode struct {
+	Code      string     `json:"code,omitempty"`
+	Operation string     `json:"operation,omitempty"`
+	Message   string     `json:"message"`
+	Children  []TreeNode `json:"children,omitempty"`
+}
+
+// ErrorTree returns the tree formed by the chain of err, or nil
+// if err is nil. It can be marshaled to JSON.
+func ErrorTree(err error) *TreeNode {
+	if err == nil {
+		return nil
+	}
+	n := treeNode(err, 0)
+	return &n
+}
+
+func treeNode(err error, depth int) TreeNode {
+	var n TreeNode
+	var children []error
+	switch v := err.(type) {
+	case *Error:
+		if v == nil {
+			return n
+		}
+		n.Code, n.Operation, n.Message = v.Code, v.Operation, v.Message
+		if v.Err != nil {
+			children = []error{v.Err}
+		}
+	case interface{ Unwrap() []error }:
+		n.Message = err.Error()
+		children = v.Unwrap()
+		if m, ok := err.(*Multi); ok {
+			n.Message = strconv.Itoa(m.Len()) + " error(s) occurred"
+		}
+	case interface{ Unwrap() error }:
+		n.Message = err.Error()
+		if c := v.Unwrap(); c != nil {
+			children = []error{c}
+		}
+	default:
+		n.Message = err.Error()
+	}
+	if depth >= maxTreeDepth {
+		return n
+	}
+	for _, c := range children {
+		if c != nil {
+			n.Children = append(n.Children, treeNode(c, depth+1))
+		}
+	}
+	return n
+}
+
+// Tree returns an indented tree view of the chain of err showing
+// how errors are nested, one error per line.
+func Tree(err error) string {
+	n := ErrorTree(err)
+	if n == nil {
+		return ""
+	}
+	var buf strings.Builder
+	buf.WriteString(n.label())
+	n.writeChildren(&buf, "")
+	return buf.String()
+}
+
+func (n TreeNode) label() string {
+	var buf strings.Builder
+	if n.Code != "" {
+		buf.WriteString("<" + n.Code + "> ")
+	}
+	if n.Operation != "" {
+		buf.WriteString(n.Operation + ": ")
+	}
+	buf.WriteString(n.Message)
+	return strings.TrimSuffix(strings.TrimSpace(buf.String()), ":")
+}
+
+func (n TreeNode) writeChildren(buf *strings.Builder, prefix string) {
+	for i, c := range n.Children {
+		branch, next := "├── ", "│   "
+		if i == len(n.Children)-1 {
+			branch, next = "└── ", "    "
+		}
+		buf.WriteString("\n" + prefix + branch + c.label())
+		c.writeChildren(buf, prefix+next)
+	}
+}
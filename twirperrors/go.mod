@@ -0,0 +1,10 @@
+module github.com/oarkflow/errors/twirperrors
+
+go 1.20
+
+require (
+	github.com/oarkflow/errors v0.0.0
+	github.com/twitchtv/twirp v8.1.3+incompatible
+)
+
+replace github.com/oarkflow/errors => ../
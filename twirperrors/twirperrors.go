@@ -0,0 +1,102 @@
+// Package twirperrors converts application errors to and from
+// Twirp errors.
+package twirperrors
+
+import (
+	"encoding/json"
+
+	"github.com/oarkflow/errors"
+	"github.com/oarkflow/errors/internal/rpccode"
+	"github.com/twitchtv/twirp"
+)
+
+// Meta keys carrying the application error details.
+const (
+	MetaCode      = "code"
+	MetaOperation = "operation"
+	MetaMetadata  = "metadata"
+)
+
+var twirpCodes = map[uint32]twirp.ErrorCode{
+	rpccode.Canceled:           twirp.Canceled,
+	rpccode.Unknown:            twirp.Unknown,
+	rpccode.InvalidArgument:    twirp.InvalidArgument,
+	rpccode.DeadlineExceeded:   twirp.DeadlineExceeded,
+	rpccode.NotFound:           twirp.NotFound,
+	rpccode.AlreadyExists:      twirp.AlreadyExists,
+	rpccode.PermissionDenied:   twirp.PermissionDenied,
+	rpccode.ResourceExhausted:  twirp.ResourceExhausted,
+	rpccode.FailedPrecondition: twirp.FailedPrecondition,
+	rpccode.Aborted:            twirp.Aborted,
+	rpccode.OutOfRange:         twirp.OutOfRange,
+	rpccode.Unimplemented:      twirp.Unimplemented,
+	rpccode.Internal:           twirp.Internal,
+	rpccode.Unavailable:        twirp.Unavailable,
+	rpccode.DataLoss:           twirp.DataLoss,
+	rpccode.Unauthenticated:    twirp.Unauthenticated,
+}
+
+// ToTwirp converts err to a twirp.Error. The Twirp code is
+// derived from the application code, which is carried along with
+// the operation and the JSON encoded metadata in the error meta,
+// after being shaped by errors.Outbound. Errors already
+// implementing twirp.Error are returned unchanged, and nil returns
+// nil.
+func ToTwirp(err error) twirp.Error {
+	if err == nil {
+		return nil
+	}
+	var te twirp.Error
+	if errors.As(err, &te) {
+		return te
+	}
+	e := errors.Outbound(errors.From(err))
+	code, ok := twirpCodes[rpccode.FromCode(errors.Code(e))]
+	if !ok {
+		code = twirp.Unknown
+	}
+	te = twirp.NewError(code, errors.Message(e)).WithMeta(MetaCode, errors.Code(e))
+	if e.Operation != "" {
+		te = te.WithMeta(MetaOperation, e.Operation)
+	}
+	if metadata := e.Fields(); len(metadata) > 0 {
+		if b, mErr := json.Marshal(metadata); mErr == nil {
+			te = te.WithMeta(MetaMetadata, string(b))
+		}
+	}
+	return te
+}
+
+// FromTwirp converts a twirp.Error found in the chain of err to
+// an Error, restoring the application code, operation and
+// metadata from the error meta when present. Other errors are
+// promoted with errors.From, and nil returns nil.
+func FromTwirp(err error) *errors.Error {
+	if err == nil {
+		return nil
+	}
+	var te twirp.Error
+	if !errors.As(err, &te) {
+		return errors.From(err)
+	}
+	code := te.Meta(MetaCode)
+	if code == "" {
+		code = errors.INTERNAL
+		for rpc, tc := range twirpCodes {
+			if tc == te.Code() {
+				code = rpccode.ToCode(rpc)
+				break
+			}
+		}
+	}
+	e := errors.NewSkip(1, te, te.Msg(), code, te.Meta(MetaOperation))
+	if raw := te.Meta(MetaMetadata); raw != "" {
+		var metadata map[string]any
+		if json.Unmarshal([]byte(raw), &metadata) == nil {
+			for k, v := range metadata {
+				e.WithField(k, v)
+			}
+		}
+	}
+	return e
+}
@@ -7,53 +7,151 @@ import (
 	"unsafe"
 )
 
-// Code returns the code of the root error, if available.
-// Otherwise, returns INTERNAL.
+// Code returns the code of the root error, if available,
+// descending into joined errors as chosen by
+// DefaultJoinStrategy. Otherwise, returns INTERNAL.
 func Code(err error) string {
 	if err == nil {
 		return ""
-	} else if e, ok := err.(*Error); ok && e.Code != "" {
+	} else if e, ok := err.(*Error); ok && e == nil {
+		return ""
+	}
+	if e := findError(err, 0, func(e *Error) bool { return e.Code != "" }); e != nil {
 		return e.Code
-	} else if ok && e.Err != nil {
-		return Code(e.Err)
 	}
 	return INTERNAL
 }
 
 // Message returns the human-readable message of the error,
-// if available. Otherwise, returns a generic error
-// message.
+// if available. Otherwise, returns the fallback message of its
+// code, see SetFallbackMessage.
 func Message(err error) string {
 	if err == nil {
 		return ""
-	} else if e, ok := err.(*Error); ok && e.Message != "" {
+	} else if e, ok := err.(*Error); ok && e == nil {
+		return ""
+	}
+	if msg := message(err); msg != "" {
+		return msg
+	}
+	return FallbackMessage(Code(err))
+}
+
+// message returns the first message found in the chain of
+// Errors wrapping each other, descending into joined errors.
+func message(err error) string {
+	if e := findError(err, 0, func(e *Error) bool { return e.Message != "" }); e != nil {
 		return e.Message
-	} else if ok && e.Err != nil {
-		return Message(e.Err)
 	}
-	return GlobalError
+	return ""
 }
 
-// ToError Returns an application error from input. If The type
-// is not of type Error, nil will be returned.
+// From returns the first Error found in the chain of err. If
+// there is none, err is wrapped in an Error with the code of the
+// first matching translation, see Translate, or a UNKNOWN error
+// code, and a stack captured at the caller, keeping err as the
+// cause so Is and As still match it. If err is nil, From returns
+// nil.
+func From(err error) *Error {
+	return from(1, err)
+}
+
+func from(skip int, err error) *Error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if As(err, &e) {
+		return e
+	}
+	e = buildError(skip+1, err, "", UNKNOWN, "")
+	if t, ok := translate(err); ok {
+		t.apply(e)
+	}
+	notify(e)
+	return e
+}
+
+// ToError Returns an application error from input. Errors are
+// promoted with From, and Error values are copied to an *Error
+// owning its metadata. Slices of errors are collected into a
+// Multi wrapped in an Error whose code is chosen by the
+// DefaultJoinStrategy; a single error is promoted on its own.
+// Strings and other values are turned into an UNKNOWN Error. Only
+// a nil input, or slices without any non-nil error, return nil.
+//
+// Deprecated: use From, which captures a stack and keeps the
+// original error in the chain.
 func ToError(err any) *Error {
 	switch v := err.(type) {
+	case nil:
+		return nil
 	case *Error:
 		return v
 	case Error:
-		return &v
+		return (&v).clone()
+	case []*Error:
+		errs := make([]error, 0, len(v))
+		for _, e := range v {
+			if e != nil {
+				errs = append(errs, e)
+			}
+		}
+		return fromErrors(1, errs)
+	case []error:
+		return fromErrors(1, v)
 	case error:
-		return &Error{Err: fmt.Errorf(v.Error())}
+		return from(1, v)
 	case string:
-		return &Error{Err: fmt.Errorf(v)}
+		return from(1, New(v))
 	default:
+		return from(1, fmt.Errorf("%v", v))
+	}
+}
+
+// fromErrors promotes the non-nil errors of errs as described by
+// ToError.
+func fromErrors(skip int, errs []error) *Error {
+	m := &Multi{}
+	m.Append(errs...)
+	switch m.Len() {
+	case 0:
 		return nil
+	case 1:
+		return from(skip+1, m.Errors[0])
+	}
+	e := buildError(skip+1, m, "", Code(m), "")
+	notify(e)
+	return e
+}
+
+// IsNil reports whether err is nil, including the pitfall of a
+// nil pointer stored in a non-nil error interface:
+//
+//	var e *Error
+//	var err error = e // err != nil, but IsNil(err) is true
+func IsNil(err error) bool {
+	return isNilValue(err)
+}
+
+// Location returns the file and line of the caller skip frames
+// above the caller of Location: 0 is the caller itself, 1 its
+// caller, and so on. It returns "" and 0 if there is no such
+// frame.
+func Location(skip int) (file string, line int) {
+	_, file, line, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return "", 0
 	}
+	return file, line
 }
 
-func getLocation() string {
-	_, file, line, _ := runtime.Caller(2)
-	return fmt.Sprintf("%s:%d", file, line)
+// FuncName returns the fully qualified name of the function skip
+// frames above the caller of FuncName, with the same skip values
+// as Location. Inlined calls are resolved to the function they
+// were written in.
+func FuncName(skip int) string {
+	return callerFuncName(skip + 1)
 }
 
 // FromByte converts byte slice to a string without memory allocation.
@@ -80,8 +178,3 @@ func ToByte(s string) (b []byte) {
 	bh.Cap = sh.Len
 	return b
 }
-
-func getCurrentFuncName() string {
-	pc, _, _, _ := runtime.Caller(1)
-	return fmt.Sprintf("%s", runtime.FuncForPC(pc).Name())
-}
@@ -0,0 +1,40 @@
+package errors
+
+// FieldViolation describes why a single field of a request
+// failed validation.
+type FieldViolation struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule,omitempty"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// NewValidation returns an Error with a INVALID error code
+// holding the field violations.
+func NewValidation(violations []FieldViolation, message, op string, disableErrorHandler ...bool) *Error {
+	e := buildError(1, nil, message, INVALID, op)
+	e.Violations = violations
+	notify(e, disableErrorHandler...)
+	return e
+}
+
+// NewValidationSkip is like NewValidation, wrapping err, and
+// records the location of the caller skip frames above the caller
+// of NewValidationSkip, see NewSkip.
+func NewValidationSkip(skip int, err error, violations []FieldViolation, message, op string, disableErrorHandler ...bool) *Error {
+	e := buildError(skip+1, err, message, INVALID, op)
+	e.Violations = violations
+	notify(e, disableErrorHandler...)
+	return e
+}
+
+// Violations returns the field violations of the first Error in
+// the chain having some.
+func Violations(err error) []FieldViolation {
+	for _, e := range chainOf(err) {
+		if len(e.Violations) > 0 {
+			return e.Violations
+		}
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+module github.com/oarkflow/errors/validation
+
+go 1.20
+
+require (
+	github.com/go-playground/validator/v10 v10.14.1
+	github.com/oarkflow/errors v0.0.0
+)
+
+require (
+	github.com/gabriel-vasile/mimetype v1.4.2 // indirect
+	github.com/go-playground/locales v0.14.1 // indirect
+	github.com/go-playground/universal-translator v0.18.1 // indirect
+	github.com/leodido/go-urn v1.2.4 // indirect
+	golang.org/x/crypto v0.11.0 // indirect
+	golang.org/x/net v0.12.0 // indirect
+	golang.org/x/sys v0.10.0 // indirect
+	golang.org/x/text v0.11.0 // indirect
+)
+
+replace github.com/oarkflow/errors => ../
@@ -0,0 +1,88 @@
+// Package validation converts go-playground/validator errors
+// into application errors holding field violations.
+package validation
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/oarkflow/errors"
+)
+
+// DefaultMessage is the message of the errors returned by
+// FromValidator.
+var DefaultMessage = "Validation failed."
+
+// New returns a validator reporting fields by their JSON names,
+// as taken from the struct tags.
+func New() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(JSONTagName)
+	return v
+}
+
+// JSONTagName returns the JSON name of the struct field. It can
+// be registered with validator.Validate.RegisterTagNameFunc.
+func JSONTagName(f reflect.StructField) string {
+	name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return f.Name
+	}
+	return name
+}
+
+// FromValidator converts validator.ValidationErrors into an
+// INVALID Error holding one violation per failed field. Other
+// errors, such as validator.InvalidValidationError, are wrapped
+// as INTERNAL errors. If err is nil, nil is returned.
+func FromValidator(err error, op string) error {
+	if err == nil {
+		return nil
+	}
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return errors.NewInternal(err, "", op)
+	}
+	violations := make([]errors.FieldViolation, 0, len(verrs))
+	for _, fe := range verrs {
+		violations = append(violations, errors.FieldViolation{
+			Field:   fieldPath(fe),
+			Rule:    fe.Tag(),
+			Param:   fe.Param(),
+			Message: message(fe),
+		})
+	}
+	return errors.NewValidationSkip(1, err, violations, DefaultMessage, op)
+}
+
+// fieldPath returns the namespace of the field without the name
+// of the validated top-level struct.
+func fieldPath(fe validator.FieldError) string {
+	ns := fe.Namespace()
+	if i := strings.Index(ns, "."); i >= 0 {
+		return ns[i+1:]
+	}
+	return fe.Field()
+}
+
+func message(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return fe.Field() + " is required"
+	case "email":
+		return fe.Field() + " must be a valid email address"
+	case "min", "gte":
+		return fe.Field() + " must be at least " + fe.Param()
+	case "max", "lte":
+		return fe.Field() + " must be at most " + fe.Param()
+	case "len":
+		return fe.Field() + " must have a length of " + fe.Param()
+	case "oneof":
+		return fe.Field() + " must be one of " + fe.Param()
+	}
+	return fe.Field() + " failed the " + fe.Tag() + " validation"
+}
@@ -0,0 +1,41 @@
+package validation_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/oarkflow/errors"
+	"github.com/oarkflow/errors/validation"
+)
+
+type signup struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+func TestFromValidator(t *testing.T) {
+	verr := validation.New().Struct(signup{Email: "nope"})
+	err := validation.FromValidator(verr, "signup")
+
+	var e *errors.Error
+	if !errors.As(err, &e) {
+		t.Fatalf("FromValidator returned %T", err)
+	}
+	if e.Code != errors.INVALID {
+		t.Errorf("code = %q, want %q", e.Code, errors.INVALID)
+	}
+	var verrs validator.ValidationErrors
+	if !errors.As(e.Unwrap(), &verrs) {
+		t.Errorf("cause = %v, want the validator error", e.Unwrap())
+	}
+	if v := errors.Violations(err); len(v) != 1 || v[0].Field != "email" || v[0].Rule != "email" {
+		t.Errorf("violations = %+v", v)
+	}
+	if got := filepath.Base(e.Caller().File); got != "validation_test.go" {
+		t.Errorf("caller file = %q, want validation_test.go", got)
+	}
+	if fl := e.FileLine(); !strings.HasPrefix(filepath.Base(fl), "validation_test.go:") {
+		t.Errorf("file line = %q, want validation_test.go", fl)
+	}
+}
@@ -0,0 +1,198 @@
+package errors
+
+import (
+	"reflect"
+)
+
+// ErrorView is a read-only view of an Error, to pass errors to
+// plugins and extensions which must not mutate the shared
+// instance. Metadata and violations are returned as deep copies,
+// with the Secret values replaced by SecretMask.
+type ErrorView interface {
+	// Code returns the code of the error, see Code.
+	Code() string
+	// Message returns the message of the error, see Message.
+	Message() string
+	// Operation returns the operation of the error.
+	Operation() string
+	// Meta returns the metadata value stored under key. Secret
+	// values stay masked.
+	Meta(key string) (any, bool)
+	// Fields returns a copy of the metadata. Secret values stay
+	// masked.
+	Fields() map[string]any
+	// Violations returns a copy of the field violations.
+	Violations() []FieldViolation
+	// Severity returns the severity of the error.
+	Severity() Severity
+	// Retryable reports whether the error may be retried.
+	Retryable() bool
+	// Cause returns the view of the wrapped Error, or nil.
+	Cause() ErrorView
+	// Error returns the string representation of the error.
+	Error() string
+}
+
+// View returns a read-only view of the error, or nil for a nil
+// error.
+func (e *Error) View() ErrorView {
+	if e == nil {
+		return nil
+	}
+	return errorView{e: e}
+}
+
+type errorView struct {
+	e *Error
+}
+
+func (v errorView) Code() string {
+	return Code(v.e)
+}
+
+func (v errorView) Message() string {
+	return Message(v.e)
+}
+
+func (v errorView) Operation() string {
+	return v.e.Operation
+}
+
+func (v errorView) Meta(key string) (any, bool) {
+	value, ok := v.e.Meta(key)
+	if !ok {
+		return nil, false
+	}
+	return viewValue(value), true
+}
+
+func (v errorView) Fields() map[string]any {
+	fields := v.e.Fields()
+	for k, value := range fields {
+		fields[k] = viewValue(value)
+	}
+	return fields
+}
+
+func (v errorView) Violations() []FieldViolation {
+	if v.e.Violations == nil {
+		return nil
+	}
+	return append([]FieldViolation(nil), v.e.Violations...)
+}
+
+func (v errorView) Severity() Severity {
+	return SeverityOf(v.e)
+}
+
+func (v errorView) Retryable() bool {
+	return IsRetryable(v.e)
+}
+
+func (v errorView) Error() string {
+	return v.e.Error()
+}
+
+func (v errorView) Cause() ErrorView {
+	var inner *Error
+	if v.e.Err == nil || !As(v.e.Err, &inner) {
+		return nil
+	}
+	return inner.View()
+}
+
+// viewValue returns a deep copy of a metadata value, with the
+// Secret values it holds replaced by SecretMask.
+func viewValue(v any) any {
+	if v == nil {
+		return nil
+	}
+	if _, ok := v.(Secret); ok {
+		return SecretMask
+	}
+	c := copyValue(reflect.ValueOf(v), 0)
+	if !c.IsValid() || !c.CanInterface() {
+		return v
+	}
+	return c.Interface()
+}
+
+var secretType = reflect.TypeOf(Secret{})
+
+// copyValue returns a deep copy of v, up to maxTreeDepth levels.
+func copyValue(v reflect.Value, depth int) reflect.Value {
+	if !v.IsValid() || depth >= maxTreeDepth {
+		return v
+	}
+	if v.Type() == secretType {
+		return reflect.ValueOf(SecretMask)
+	}
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := copyValue(v.Elem(), depth+1)
+		if !c.Type().AssignableTo(v.Type()) {
+			return v
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(c)
+		return out
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type().Elem())
+		out.Elem().Set(copyValue(v.Elem(), depth+1))
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(iter.Key(), assignable(copyValue(iter.Value(), depth+1), v.Type().Elem(), iter.Value()))
+		}
+		return out
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(assignable(copyValue(v.Index(i), depth+1), v.Type().Elem(), v.Index(i)))
+		}
+		return out
+	case reflect.Array:
+		out := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(assignable(copyValue(v.Index(i), depth+1), v.Type().Elem(), v.Index(i)))
+		}
+		return out
+	case reflect.Struct:
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if f := out.Field(i); f.CanSet() {
+				f.Set(assignable(copyValue(v.Field(i), depth+1), f.Type(), v.Field(i)))
+			}
+		}
+		return out
+	}
+	return v
+}
+
+// assignable returns c if it can be stored in a value of type t,
+// otherwise the original value orig. A Secret held in a typed
+// field, which SecretMask cannot replace, holds SecretMask.
+func assignable(c reflect.Value, t reflect.Type, orig reflect.Value) reflect.Value {
+	if c.IsValid() && c.Type().AssignableTo(t) {
+		return c
+	}
+	if orig.Type() == secretType {
+		return reflect.ValueOf(NewSecret(SecretMask))
+	}
+	return orig
+}
@@ -0,0 +1,43 @@
+package errors_test
+
+import (
+	"testing"
+
+	"github.com/oarkflow/errors"
+)
+
+func TestViewMasksSecrets(t *testing.T) {
+	e := errors.NewInvalid(nil, "login failed", "login").
+		WithField("password", errors.NewSecret("hunter2")).
+		WithField("request", map[string]any{
+			"token": errors.NewSecret("abc"),
+			"tags":  []any{"a", errors.NewSecret("b")},
+		})
+	v := e.View()
+
+	if got, _ := v.Meta("password"); got != errors.SecretMask {
+		t.Errorf("Meta(password) = %v, want %q", got, errors.SecretMask)
+	}
+	fields := v.Fields()
+	if got := fields["password"]; got != errors.SecretMask {
+		t.Errorf("Fields()[password] = %v, want %q", got, errors.SecretMask)
+	}
+	req := fields["request"].(map[string]any)
+	if got := req["token"]; got != errors.SecretMask {
+		t.Errorf("nested token = %v, want %q", got, errors.SecretMask)
+	}
+	if got := req["tags"].([]any)[1]; got != errors.SecretMask {
+		t.Errorf("nested tag = %v, want %q", got, errors.SecretMask)
+	}
+}
+
+func TestViewDeepCopies(t *testing.T) {
+	e := errors.NewInvalid(nil, "bad request", "parse").WithField("request", map[string]any{"id": 1})
+	req, _ := e.View().Meta("request")
+	req.(map[string]any)["id"] = 2
+
+	orig, _ := e.Meta("request")
+	if got := orig.(map[string]any)["id"]; got != 1 {
+		t.Errorf("original metadata mutated through the view: id = %v", got)
+	}
+}
@@ -0,0 +1,127 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sync"
+)
+
+// Warning is a non-fatal issue of an operation which otherwise
+// succeeded.
+type Warning struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Field   string `json:"field,omitempty"`
+}
+
+// Warnings accumulates the warnings of an operation. The zero
+// value is ready to use and safe for concurrent use.
+type Warnings struct {
+	mu   sync.Mutex
+	list []Warning
+}
+
+// Add records a warning.
+func (w *Warnings) Add(code, message string) {
+	w.AddWarning(Warning{Code: code, Message: message})
+}
+
+// AddWarning records a warning.
+func (w *Warnings) AddWarning(warning Warning) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.list = append(w.list, warning)
+}
+
+// AddError records err, if not nil, as a warning with its code
+// and message, so failures of optional steps can be downgraded.
+func (w *Warnings) AddError(err error) {
+	if IsNil(err) {
+		return
+	}
+	w.Add(Code(err), Message(err))
+}
+
+// Len returns the number of warnings.
+func (w *Warnings) Len() int {
+	if w == nil {
+		return 0
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.list)
+}
+
+// List returns a copy of the warnings.
+func (w *Warnings) List() []Warning {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.list) == 0 {
+		return nil
+	}
+	out := make([]Warning, len(w.list))
+	copy(out, w.list)
+	return out
+}
+
+// MarshalJSON encodes the warnings as an array.
+func (w *Warnings) MarshalJSON() ([]byte, error) {
+	list := w.List()
+	if list == nil {
+		list = []Warning{}
+	}
+	return json.Marshal(list)
+}
+
+// WriteResult writes result as JSON using the DefaultResponder.
+func WriteResult(w http.ResponseWriter, status int, result any, warnings *Warnings) {
+	DefaultResponder.WriteResult(w, status, result, warnings)
+}
+
+// WriteResult writes a successful result as JSON with the given
+// status code. If there are warnings, they are added under a
+// "warnings" key to a result encoded as an object, or the result
+// is put under "data" otherwise.
+func (rs *Responder) WriteResult(w http.ResponseWriter, status int, result any, warnings *Warnings) {
+	body, err := json.Marshal(result)
+	if err != nil {
+		rs.WriteError(w, nil, NewInternal(err, "", "errors.WriteResult"))
+		return
+	}
+	if list := warnings.List(); len(list) > 0 {
+		if body, err = withWarnings(body, list); err != nil {
+			rs.WriteError(w, nil, NewInternal(err, "", "errors.WriteResult"))
+			return
+		}
+	}
+	w.Header().Set("Content-Type", MediaJSON)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
+
+func withWarnings(body []byte, list []Warning) ([]byte, error) {
+	encoded, err := json.Marshal(list)
+	if err != nil {
+		return nil, err
+	}
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 1 && trimmed[0] == '{' {
+		var fields map[string]json.RawMessage
+		if err = json.Unmarshal(trimmed, &fields); err != nil {
+			return nil, err
+		}
+		if _, taken := fields["warnings"]; !taken {
+			fields["warnings"] = encoded
+			return json.Marshal(fields)
+		}
+	}
+	return json.Marshal(map[string]json.RawMessage{
+		"data":     trimmed,
+		"warnings": encoded,
+	})
+}
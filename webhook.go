@@ -0,0 +1,127 @@
+package errors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Webhook is a Reporter posting Slack compatible messages to
+// an incoming webhook URL.
+type Webhook struct {
+	URL    string
+	Client *http.Client
+	// MinSeverity filters out errors less severe than it.
+	MinSeverity Severity
+	// Codes, if not empty, restricts reporting to these codes.
+	Codes []string
+	// RateLimit is the maximum number of messages posted per
+	// RatePeriod. Errors above the limit are silently dropped.
+	RateLimit  int
+	RatePeriod time.Duration
+
+	mu          sync.Mutex
+	windowStart time.Time
+	sent        int
+}
+
+// NewWebhook returns a Webhook reporting Critical errors to
+// url, limited to one message per second.
+func NewWebhook(url string) *Webhook {
+	return &Webhook{
+		URL:         url,
+		MinSeverity: SeverityCritical,
+		RateLimit:   1,
+		RatePeriod:  time.Second,
+	}
+}
+
+// webhookPayload is the Slack incoming webhook payload.
+type webhookPayload struct {
+	Text string `json:"text"`
+}
+
+// Report posts the error to the webhook if it passes the
+// severity and code filters and the rate limit.
+func (w *Webhook) Report(ctx context.Context, err *Error) error {
+	if err == nil || !w.accepts(err) || !w.allow() {
+		return nil
+	}
+	body, mErr := json.Marshal(webhookPayload{Text: w.text(err)})
+	if mErr != nil {
+		return mErr
+	}
+	req, rErr := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if rErr != nil {
+		return rErr
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, dErr := client.Do(req)
+	if dErr != nil {
+		return dErr
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("errors: webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (w *Webhook) accepts(err *Error) bool {
+	if w.MinSeverity != 0 && SeverityOf(err) < w.MinSeverity {
+		return false
+	}
+	if len(w.Codes) == 0 {
+		return true
+	}
+	code := Code(err)
+	for _, c := range w.Codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Webhook) allow() bool {
+	if w.RateLimit <= 0 {
+		return true
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	now := currentTime()
+	if now.Sub(w.windowStart) >= w.RatePeriod {
+		w.windowStart = now
+		w.sent = 0
+	}
+	if w.sent >= w.RateLimit {
+		return false
+	}
+	w.sent++
+	return true
+}
+
+func (w *Webhook) text(err *Error) string {
+	var buf strings.Builder
+	buf.WriteString("*[" + SeverityOf(err).String() + "] " + Code(err) + "*")
+	if err.Operation != "" {
+		buf.WriteString(" `" + err.Operation + "`")
+	}
+	buf.WriteString("\n" + Message(err))
+	if err.Err != nil {
+		buf.WriteString("\n> " + err.Err.Error())
+	}
+	if len(err.Additional) > 0 {
+		buf.WriteString("\n```" + err.Additional.String() + "```")
+	}
+	return buf.String()
+}
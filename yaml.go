@@ -0,0 +1,82 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// MarshalYAML implements the yaml.v2 and yaml.v3 Marshaler
+// interfaces. The error is written with the fields of its JSON
+// representation, shaped by DefaultMarshalOptions.
+func (e *Error) MarshalYAML() (any, error) {
+	if e == nil {
+		return nil, nil
+	}
+	b, err := e.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var v map[string]any
+	if err = json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// UnmarshalYAML implements the yaml.v2 Unmarshaler interface,
+// also supported by yaml.v3. It reads what MarshalYAML wrote.
+func (e *Error) UnmarshalYAML(unmarshal func(any) error) error {
+	var v map[string]any
+	if err := unmarshal(&v); err != nil {
+		return err
+	}
+	normalized, err := jsonCompatible(v)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(normalized)
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalJSON(b)
+}
+
+// jsonCompatible converts the map[any]any values decoded by
+// yaml.v2 to map[string]any so they can be encoded to JSON.
+func jsonCompatible(v any) (any, error) {
+	switch t := v.(type) {
+	case map[any]any:
+		m := make(map[string]any, len(t))
+		for k, val := range t {
+			key, ok := k.(string)
+			if !ok {
+				key = fmt.Sprint(k)
+			}
+			c, err := jsonCompatible(val)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = c
+		}
+		return m, nil
+	case map[string]any:
+		for k, val := range t {
+			c, err := jsonCompatible(val)
+			if err != nil {
+				return nil, err
+			}
+			t[k] = c
+		}
+		return t, nil
+	case []any:
+		for i, val := range t {
+			c, err := jsonCompatible(val)
+			if err != nil {
+				return nil, err
+			}
+			t[i] = c
+		}
+		return t, nil
+	}
+	return v, nil
+}